@@ -14,8 +14,8 @@ import (
 )
 
 const (
-	version = "1.0.2"
-	baseURL = "https://api.abuseipdb.com/api/v2"
+	version        = "1.0.2"
+	defaultBaseURL = "https://api.abuseipdb.com/api/v2"
 )
 
 var (
@@ -89,7 +89,19 @@ const (
 // Use CreateClient to initialise a new client.
 type Client struct {
 	httpClient *http.Client
+	baseURL    string
+	flights    flightGroup
 	APIKey     string
+	// Plan is the subscription plan of the API key, which determines the limits applied by the API.
+	// This defaults to PlanFree.
+	Plan Plan
+	// Sanitizer, if set, is applied to report comments before they are submitted.
+	Sanitizer *Sanitizer
+	// SkipAddressValidation disables the validation of IP addresses passed to Check and Report.
+	// Addresses are still normalised, but private and reserved addresses are sent to the API as-is.
+	SkipAddressValidation bool
+	// Hooks are called with an Event after each call to Check or Report which reaches the API.
+	Hooks []EventHook
 }
 
 // RequestOptions stores additional options used when making requests to the AbuseIPDB API,
@@ -105,6 +117,9 @@ type RequestError struct {
 	StatusCode int
 	Details    []string
 	Raw        string
+	// RetryAfter is how long the API asked the client to wait before making another request,
+	// taken from the Retry-After header when the rate limit has been exceeded.
+	RetryAfter time.Duration
 }
 
 type ErrorResponse struct {
@@ -123,7 +138,8 @@ func NewClient(apiKey string) *Client {
 		httpClient: &http.Client{
 			Timeout: time.Minute,
 		},
-		APIKey: apiKey,
+		baseURL: defaultBaseURL,
+		APIKey:  apiKey,
 	}
 
 	return &client
@@ -138,7 +154,13 @@ func (c *Client) makeRequest(method string, endpoint string, options RequestOpti
 		body = bytes.NewReader(options.Body)
 	}
 
-	reqUrl := fmt.Sprintf("%s%s%s", baseURL, endpoint, buildQueryString(options.Params))
+	base := c.baseURL
+
+	if base == "" {
+		base = defaultBaseURL
+	}
+
+	reqUrl := fmt.Sprintf("%s%s%s", base, endpoint, buildQueryString(options.Params))
 	req, err := http.NewRequest(method, reqUrl, body)
 
 	if err != nil {
@@ -169,6 +191,7 @@ func (c *Client) makeRequest(method string, endpoint string, options RequestOpti
 
 		requestError := RequestError{
 			StatusCode: res.StatusCode,
+			RetryAfter: parseRetryAfter(res.Header.Get("Retry-After")),
 		}
 
 		if err == nil {
@@ -195,6 +218,23 @@ func (c *Client) makeRequest(method string, endpoint string, options RequestOpti
 	return res, nil
 }
 
+// parseRetryAfter parses the value of a Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil && date.After(time.Now()) {
+		return time.Until(date)
+	}
+
+	return 0
+}
+
 func buildQueryString(params map[string]string) string {
 	if len(params) == 0 {
 		return ""
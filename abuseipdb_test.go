@@ -3,12 +3,25 @@ package abuseipdb
 import (
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"testing"
 	"time"
 )
 
+// newTestClient returns a client which sends requests to a local test server using the handler provided.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient("testing123")
+	client.baseURL = server.URL
+
+	return client
+}
+
 func TestBuildCategoryString(t *testing.T) {
 	got := buildCategoryString(nil)
 
@@ -202,3 +215,19 @@ func TestMakeRequest_POST(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("120"); got != 2*time.Minute {
+		t.Errorf("parseRetryAfter: expected 2m0s, got %s", got)
+	}
+
+	if got := parseRetryAfter(""); got != 0 {
+		t.Errorf("parseRetryAfter: expected 0s, got %s", got)
+	}
+
+	got := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+
+	if got < 59*time.Minute || got > time.Hour {
+		t.Errorf("parseRetryAfter: expected roughly 1h0m0s, got %s", got)
+	}
+}
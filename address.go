@@ -0,0 +1,162 @@
+package abuseipdb
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// InvalidAddressError is returned when an IP address can't be checked or reported,
+// either because it is malformed or because it belongs to a range which isn't publicly routable.
+type InvalidAddressError struct {
+	Address string
+	Reason  string
+}
+
+func (e InvalidAddressError) Error() string {
+	return fmt.Sprintf("abuseipdb: invalid ip address %q: %s", e.Address, e.Reason)
+}
+
+// The reasons an InvalidAddressError may be returned.
+const (
+	ReasonMalformed   = "malformed"
+	ReasonPrivate     = "private"
+	ReasonLoopback    = "loopback"
+	ReasonLinkLocal   = "link-local"
+	ReasonMulticast   = "multicast"
+	ReasonReserved    = "reserved"
+	ReasonUnspecified = "unspecified"
+)
+
+type addressRange struct {
+	network *net.IPNet
+	reason  string
+}
+
+// nonPublicRanges lists the special-purpose ranges from the IANA IPv4 and IPv6 registries
+// which can't be the source of abuse on the public internet.
+var nonPublicRanges = buildAddressRanges(map[string]string{
+	"0.0.0.0/8":       ReasonReserved,
+	"10.0.0.0/8":      ReasonPrivate,
+	"100.64.0.0/10":   ReasonPrivate,
+	"127.0.0.0/8":     ReasonLoopback,
+	"169.254.0.0/16":  ReasonLinkLocal,
+	"172.16.0.0/12":   ReasonPrivate,
+	"192.0.0.0/24":    ReasonReserved,
+	"192.0.2.0/24":    ReasonReserved,
+	"192.88.99.0/24":  ReasonReserved,
+	"192.168.0.0/16":  ReasonPrivate,
+	"198.18.0.0/15":   ReasonReserved,
+	"198.51.100.0/24": ReasonReserved,
+	"203.0.113.0/24":  ReasonReserved,
+	"224.0.0.0/4":     ReasonMulticast,
+	"240.0.0.0/4":     ReasonReserved,
+	"::/128":          ReasonUnspecified,
+	"::1/128":         ReasonLoopback,
+	"100::/64":        ReasonReserved,
+	"2001:db8::/32":   ReasonReserved,
+	"fc00::/7":        ReasonPrivate,
+	"fe80::/10":       ReasonLinkLocal,
+	"ff00::/8":        ReasonMulticast,
+})
+
+func buildAddressRanges(ranges map[string]string) []addressRange {
+	addressRanges := make([]addressRange, 0, len(ranges))
+
+	for cidr, reason := range ranges {
+		_, network, err := net.ParseCIDR(cidr)
+
+		if err != nil {
+			panic(err)
+		}
+
+		addressRanges = append(addressRanges, addressRange{
+			network: network,
+			reason:  reason,
+		})
+	}
+
+	return addressRanges
+}
+
+// NormalizeIP validates an IP address (either v4 or v6) and returns it in its canonical textual form.
+// IPv4-mapped IPv6 addresses are unwrapped to their IPv4 form, and IPv6 addresses are formatted as per RFC 5952.
+// An InvalidAddressError is returned if the address is malformed, or is a private, loopback, link-local,
+// multicast or otherwise reserved address.
+func NormalizeIP(address string) (string, error) {
+	ip := net.ParseIP(strings.TrimSpace(address))
+
+	if ip == nil {
+		return "", InvalidAddressError{
+			Address: address,
+			Reason:  ReasonMalformed,
+		}
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	for _, addressRange := range nonPublicRanges {
+		if addressRange.network.Contains(ip) {
+			return "", InvalidAddressError{
+				Address: address,
+				Reason:  addressRange.reason,
+			}
+		}
+	}
+
+	return ip.String(), nil
+}
+
+// canonicalIP formats an IP address in its canonical textual form without rejecting non-public ranges.
+// If the address can't be parsed, it is returned unchanged.
+func canonicalIP(address string) string {
+	ip := net.ParseIP(strings.TrimSpace(address))
+
+	if ip == nil {
+		return address
+	}
+
+	return ip.String()
+}
+
+// sortEntries sorts blacklist entries by IP address, with IPv4 addresses before IPv6 addresses,
+// so that output generated from them is deterministic.
+func sortEntries(entries []BlacklistEntry) {
+	keys := make(map[string][]byte, len(entries))
+
+	for _, entry := range entries {
+		keys[entry.IPAddress] = ipSortKey(entry.IPAddress)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return bytes.Compare(keys[entries[i].IPAddress], keys[entries[j].IPAddress]) < 0
+	})
+}
+
+// ipSortKey returns a key which orders IPv4 addresses before IPv6 addresses, and addresses numerically within each.
+// Addresses which can't be parsed are ordered last.
+func ipSortKey(address string) []byte {
+	ip := net.ParseIP(address)
+
+	if ip == nil {
+		return append([]byte{2}, address...)
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return append([]byte{0}, ip4...)
+	}
+
+	return append([]byte{1}, ip...)
+}
+
+func (c *Client) normalizeIP(address string) (string, error) {
+	if c.SkipAddressValidation {
+		return canonicalIP(address), nil
+	}
+
+	return NormalizeIP(address)
+}
@@ -0,0 +1,69 @@
+package abuseipdb
+
+import "testing"
+
+func TestNormalizeIP(t *testing.T) {
+	valid := map[string]string{
+		"1.1.1.1":                  "1.1.1.1",
+		" 8.8.8.8 ":                "8.8.8.8",
+		"::ffff:1.1.1.1":           "1.1.1.1",
+		"2606:4700:4700:0:0:0:0:1": "2606:4700:4700::1",
+		"2606:4700:4700::ABCD":     "2606:4700:4700::abcd",
+	}
+
+	for input, expected := range valid {
+		got, err := NormalizeIP(input)
+
+		if err != nil {
+			t.Errorf(`NormalizeIP: expected err to be nil for "%s", got %v`, input, err)
+		} else if got != expected {
+			t.Errorf(`NormalizeIP: expected "%s", got "%s"`, expected, got)
+		}
+	}
+
+	invalid := map[string]string{
+		"not an ip":       ReasonMalformed,
+		"1.1.1.1.1":       ReasonMalformed,
+		"172.16.0.4":      ReasonPrivate,
+		"::ffff:10.0.0.1": ReasonPrivate,
+		"fd00::1":         ReasonPrivate,
+		"127.0.0.1":       ReasonLoopback,
+		"::1":             ReasonLoopback,
+		"169.254.1.1":     ReasonLinkLocal,
+		"fe80::1":         ReasonLinkLocal,
+		"224.0.0.1":       ReasonMulticast,
+		"ff02::1":         ReasonMulticast,
+		"192.0.2.1":       ReasonReserved,
+		"2001:db8::1":     ReasonReserved,
+		"0.0.0.0":         ReasonReserved,
+		"::":              ReasonUnspecified,
+	}
+
+	for input, reason := range invalid {
+		_, err := NormalizeIP(input)
+
+		if addressError, ok := err.(InvalidAddressError); ok {
+			if addressError.Reason != reason {
+				t.Errorf(`NormalizeIP: expected reason for "%s" to be "%s", got "%s"`, input, reason, addressError.Reason)
+			}
+		} else {
+			t.Errorf(`NormalizeIP: expected err for "%s" to be of type InvalidAddressError, got %v`, input, err)
+		}
+	}
+}
+
+func TestClient_normalizeIP(t *testing.T) {
+	client := NewClient("")
+	client.SkipAddressValidation = true
+
+	got, err := client.normalizeIP("::ffff:172.16.0.4")
+
+	if err != nil {
+		t.Logf("normalizeIP: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if got != "172.16.0.4" {
+		t.Errorf(`normalizeIP: expected "172.16.0.4", got "%s"`, got)
+	}
+}
@@ -0,0 +1,276 @@
+package abuseipdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"sort"
+)
+
+// AggregatedPrefix represents a network covering one or more blacklist entries, produced by AggregateBlacklist or
+// BlacklistSet.Aggregate.
+type AggregatedPrefix struct {
+	// Network is the prefix in CIDR notation.
+	Network string
+	// Entries is the number of blacklist entries covered by the prefix.
+	Entries int
+	// MinScore and MaxScore are the lowest and highest abuse confidence scores of the entries covered by the prefix.
+	MinScore int
+	MaxScore int
+}
+
+type aggregateConfig struct {
+	promoteMembers int
+	promoteIPv4    int
+	promoteIPv6    int
+}
+
+var defaultAggregateConfig = aggregateConfig{
+	promoteMembers: 0,
+	promoteIPv4:    24,
+	promoteIPv6:    64,
+}
+
+// AggregateOption sets an optional parameter for calls to AggregateBlacklist and BlacklistSet.Aggregate.
+type AggregateOption func(*aggregateConfig)
+
+// PromoteMembers returns an AggregateOption that replaces the entries in a network with the whole network
+// once at least the number of entries provided fall within it, even if the network isn't fully listed.
+// The size of the network is set with PromotePrefix. By default, networks aren't promoted.
+func PromoteMembers(entries int) AggregateOption {
+	return func(config *aggregateConfig) {
+		config.promoteMembers = entries
+	}
+}
+
+// PromotePrefix returns an AggregateOption that sets the prefix lengths of the networks promoted by PromoteMembers.
+// The default values are /24 for IPv4 and /64 for IPv6.
+func PromotePrefix(ipv4 int, ipv6 int) AggregateOption {
+	return func(config *aggregateConfig) {
+		config.promoteIPv4 = ipv4
+		config.promoteIPv6 = ipv6
+	}
+}
+
+// aggregateItem is a prefix being aggregated. IPv4 addresses use the first 4 bytes of the address.
+type aggregateItem struct {
+	address  [16]byte
+	ones     int
+	entries  int
+	minScore int
+	maxScore int
+}
+
+// AggregateBlacklist collapses the addresses in a blacklist into the smallest set of networks which cover exactly the
+// same addresses, unless networks are promoted with PromoteMembers. Prefixes are returned in address order, with
+// IPv4 prefixes first. Addresses which can't be parsed are left out.
+func AggregateBlacklist(response *BlacklistResponse, options ...AggregateOption) []AggregatedPrefix {
+	var ipv4, ipv6 []aggregateItem
+
+	if response != nil {
+		for _, entry := range response.Data {
+			ip := net.ParseIP(canonicalIP(entry.IPAddress))
+
+			if ip == nil {
+				continue
+			}
+
+			item := aggregateItem{
+				entries:  1,
+				minScore: entry.AbuseConfidenceScore,
+				maxScore: entry.AbuseConfidenceScore,
+			}
+
+			if ip4 := ip.To4(); ip4 != nil {
+				copy(item.address[:], ip4)
+				item.ones = 32
+				ipv4 = append(ipv4, item)
+			} else {
+				copy(item.address[:], ip)
+				item.ones = 128
+				ipv6 = append(ipv6, item)
+			}
+		}
+	}
+
+	return aggregate(ipv4, ipv6, options)
+}
+
+// Aggregate collapses the addresses and networks in the set into the smallest set of networks which cover exactly
+// the same addresses, unless networks are promoted with PromoteMembers. Each network added with AddNetwork counts as
+// a single entry. Prefixes are returned in address order, with IPv4 prefixes first.
+func (s *BlacklistSet) Aggregate(options ...AggregateOption) []AggregatedPrefix {
+	s.mu.RLock()
+
+	ipv4 := make([]aggregateItem, 0, len(s.ipv4))
+	ipv6 := make([]aggregateItem, 0, len(s.ipv6))
+
+	for key, value := range s.ipv4 {
+		item := newAggregateItem(32, value)
+		binary.BigEndian.PutUint32(item.address[:], key)
+		ipv4 = append(ipv4, item)
+	}
+
+	for key, value := range s.ipv6 {
+		item := newAggregateItem(128, value)
+		item.address = key
+		ipv6 = append(ipv6, item)
+	}
+
+	walkNetworks(s.networks4, make(net.IP, net.IPv4len), 0, func(ip net.IP, prefix int, value blacklistValue) {
+		item := newAggregateItem(prefix, value)
+		copy(item.address[:], ip)
+		ipv4 = append(ipv4, item)
+	})
+
+	walkNetworks(s.networks6, make(net.IP, net.IPv6len), 0, func(ip net.IP, prefix int, value blacklistValue) {
+		item := newAggregateItem(prefix, value)
+		copy(item.address[:], ip)
+		ipv6 = append(ipv6, item)
+	})
+
+	s.mu.RUnlock()
+
+	return aggregate(ipv4, ipv6, options)
+}
+
+func newAggregateItem(ones int, value blacklistValue) aggregateItem {
+	return aggregateItem{
+		ones:     ones,
+		entries:  1,
+		minScore: int(value.score),
+		maxScore: int(value.score),
+	}
+}
+
+func aggregate(ipv4 []aggregateItem, ipv6 []aggregateItem, options []AggregateOption) []AggregatedPrefix {
+	config := defaultAggregateConfig
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	if config.promoteMembers > 0 {
+		promote(ipv4, config.promoteIPv4, config.promoteMembers)
+		promote(ipv6, config.promoteIPv6, config.promoteMembers)
+	}
+
+	prefixes := make([]AggregatedPrefix, 0, len(ipv4)+len(ipv6))
+	prefixes = appendAggregated(prefixes, collapse(ipv4), net.IPv4len)
+	prefixes = appendAggregated(prefixes, collapse(ipv6), net.IPv6len)
+
+	return prefixes
+}
+
+// promote replaces every item in a network with the network itself, if at least members items fall within it.
+// Items which are already larger than the network are left alone. The duplicates are merged by collapse.
+func promote(items []aggregateItem, ones int, members int) {
+	counts := make(map[[16]byte]int)
+
+	for _, item := range items {
+		if item.ones >= ones {
+			counts[maskAddress(item.address, ones)]++
+		}
+	}
+
+	for i, item := range items {
+		if item.ones >= ones && counts[maskAddress(item.address, ones)] >= members {
+			items[i].address = maskAddress(item.address, ones)
+			items[i].ones = ones
+		}
+	}
+}
+
+// collapse merges items which are covered by another item, and pairs of sibling items into their parent,
+// until no more items can be merged.
+func collapse(items []aggregateItem) []aggregateItem {
+	for i := range items {
+		items[i].address = maskAddress(items[i].address, items[i].ones)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if c := bytes.Compare(items[i].address[:], items[j].address[:]); c != 0 {
+			return c < 0
+		}
+
+		return items[i].ones < items[j].ones
+	})
+
+	stack := make([]aggregateItem, 0, len(items))
+
+	for _, item := range items {
+		if top := len(stack) - 1; top >= 0 && stack[top].covers(item) {
+			stack[top].merge(item)
+
+			continue
+		}
+
+		stack = append(stack, item)
+
+		for len(stack) >= 2 {
+			left, right := stack[len(stack)-2], stack[len(stack)-1]
+
+			if left.ones != right.ones || left.ones == 0 ||
+				maskAddress(left.address, left.ones-1) != maskAddress(right.address, right.ones-1) {
+				break
+			}
+
+			left.ones--
+			left.merge(right)
+			stack = append(stack[:len(stack)-2], left)
+		}
+	}
+
+	return stack
+}
+
+func (a aggregateItem) covers(b aggregateItem) bool {
+	return a.ones <= b.ones && maskAddress(b.address, a.ones) == a.address
+}
+
+func (a *aggregateItem) merge(b aggregateItem) {
+	a.entries += b.entries
+
+	if b.minScore < a.minScore {
+		a.minScore = b.minScore
+	}
+
+	if b.maxScore > a.maxScore {
+		a.maxScore = b.maxScore
+	}
+}
+
+// maskAddress returns the address with every bit after the first ones bits cleared.
+func maskAddress(address [16]byte, ones int) [16]byte {
+	for i := range address {
+		switch {
+		case ones >= 8:
+			ones -= 8
+		case ones > 0:
+			address[i] &= ^byte(0) << uint(8-ones)
+			ones = 0
+		default:
+			address[i] = 0
+		}
+	}
+
+	return address
+}
+
+func appendAggregated(prefixes []AggregatedPrefix, items []aggregateItem, length int) []AggregatedPrefix {
+	for _, item := range items {
+		network := net.IPNet{
+			IP:   net.IP(item.address[:length]),
+			Mask: net.CIDRMask(item.ones, length*8),
+		}
+
+		prefixes = append(prefixes, AggregatedPrefix{
+			Network:  network.String(),
+			Entries:  item.entries,
+			MinScore: item.minScore,
+			MaxScore: item.maxScore,
+		})
+	}
+
+	return prefixes
+}
@@ -0,0 +1,69 @@
+package abuseipdb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAggregateBlacklist(t *testing.T) {
+	response := testBlacklist(testExportBlacklist().Meta.GeneratedAt,
+		BlacklistEntry{IPAddress: "192.0.2.0", AbuseConfidenceScore: 50},
+		BlacklistEntry{IPAddress: "192.0.2.1", AbuseConfidenceScore: 100},
+		BlacklistEntry{IPAddress: "192.0.2.3", AbuseConfidenceScore: 80},
+		BlacklistEntry{IPAddress: "192.0.2.2", AbuseConfidenceScore: 60},
+		BlacklistEntry{IPAddress: "192.0.2.4", AbuseConfidenceScore: 70},
+		BlacklistEntry{IPAddress: "198.51.100.9", AbuseConfidenceScore: 40},
+		BlacklistEntry{IPAddress: "2001:db8::", AbuseConfidenceScore: 90},
+		BlacklistEntry{IPAddress: "2001:db8::1", AbuseConfidenceScore: 95},
+		BlacklistEntry{IPAddress: "invalid", AbuseConfidenceScore: 100},
+	)
+
+	expected := []AggregatedPrefix{
+		{Network: "192.0.2.0/30", Entries: 4, MinScore: 50, MaxScore: 100},
+		{Network: "192.0.2.4/32", Entries: 1, MinScore: 70, MaxScore: 70},
+		{Network: "198.51.100.9/32", Entries: 1, MinScore: 40, MaxScore: 40},
+		{Network: "2001:db8::/127", Entries: 2, MinScore: 90, MaxScore: 95},
+	}
+
+	if prefixes := AggregateBlacklist(response); !reflect.DeepEqual(prefixes, expected) {
+		t.Errorf("AggregateBlacklist: expected %+v, got %+v", expected, prefixes)
+	}
+
+	expected = []AggregatedPrefix{
+		{Network: "192.0.2.0/24", Entries: 5, MinScore: 50, MaxScore: 100},
+		{Network: "198.51.100.9/32", Entries: 1, MinScore: 40, MaxScore: 40},
+		{Network: "2001:db8::/64", Entries: 2, MinScore: 90, MaxScore: 95},
+	}
+
+	if prefixes := AggregateBlacklist(response, PromoteMembers(2)); !reflect.DeepEqual(prefixes, expected) {
+		t.Errorf("AggregateBlacklist: expected %+v with PromoteMembers(2), got %+v", expected, prefixes)
+	}
+
+	expected = []AggregatedPrefix{
+		{Network: "192.0.2.0/29", Entries: 5, MinScore: 50, MaxScore: 100},
+		{Network: "198.51.100.9/32", Entries: 1, MinScore: 40, MaxScore: 40},
+		{Network: "2001:db8::/127", Entries: 2, MinScore: 90, MaxScore: 95},
+	}
+
+	if prefixes := AggregateBlacklist(response, PromoteMembers(5), PromotePrefix(29, 64)); !reflect.DeepEqual(prefixes, expected) {
+		t.Errorf("AggregateBlacklist: expected %+v with PromotePrefix(29, 64), got %+v", expected, prefixes)
+	}
+}
+
+func TestBlacklistSet_Aggregate(t *testing.T) {
+	set := NewBlacklistSet(nil)
+	_ = set.Add("192.0.2.200", 30, testExportBlacklist().Meta.GeneratedAt)
+	_ = set.Add("192.0.2.1", 90, testExportBlacklist().Meta.GeneratedAt)
+	_ = set.AddNetwork("192.0.2.128/25", 60)
+	_ = set.AddNetwork("192.0.2.0/25", 70)
+	_ = set.AddNetwork("2001:db8::/48", 20)
+
+	expected := []AggregatedPrefix{
+		{Network: "192.0.2.0/24", Entries: 4, MinScore: 30, MaxScore: 90},
+		{Network: "2001:db8::/48", Entries: 1, MinScore: 20, MaxScore: 20},
+	}
+
+	if prefixes := set.Aggregate(); !reflect.DeepEqual(prefixes, expected) {
+		t.Errorf("BlacklistSet.Aggregate: expected %+v, got %+v", expected, prefixes)
+	}
+}
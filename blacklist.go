@@ -3,6 +3,7 @@ package abuseipdb
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"strconv"
 	"time"
@@ -13,11 +14,14 @@ type BlacklistResponse struct {
 	Meta struct {
 		GeneratedAt time.Time `json:"generatedAt"`
 	} `json:"meta"`
-	Data []struct {
-		IPAddress            string    `json:"ipAddress"`
-		AbuseConfidenceScore int       `json:"abuseConfidenceScore"`
-		LastReportedAt       time.Time `json:"lastReportedAt"`
-	} `json:"data"`
+	Data []BlacklistEntry `json:"data"`
+}
+
+// BlacklistEntry represents a single IP address in the AbuseIPDB blacklist.
+type BlacklistEntry struct {
+	IPAddress            string    `json:"ipAddress"`
+	AbuseConfidenceScore int       `json:"abuseConfidenceScore"`
+	LastReportedAt       time.Time `json:"lastReportedAt"`
 }
 
 type blacklistConfig struct {
@@ -57,6 +61,8 @@ func ConfidenceMinimum(score int) BlacklistOption {
 }
 
 // Blacklist will return a list of the most reported IP addresses.
+// Concurrent calls with the same options share a single request, and receive the same response,
+// which must not be modified.
 func (c *Client) Blacklist(options ...BlacklistOption) (*BlacklistResponse, error) {
 	config := defaultBlacklistConfig
 
@@ -80,27 +86,38 @@ func (c *Client) Blacklist(options ...BlacklistOption) (*BlacklistResponse, erro
 
 	params["limit"] = strconv.Itoa(config.limit)
 
-	res, err := c.makeRequest("GET", "/blacklist", RequestOptions{
-		Params: params,
-	})
+	// Concurrent calls with the same options share a single request.
+	key := fmt.Sprintf("blacklist:%d:%d", config.confidenceMinimum, config.limit)
 
-	if err != nil {
-		return nil, err
-	}
+	value, err := c.flights.do(key, func() (interface{}, error) {
+		res, err := c.makeRequest("GET", "/blacklist", RequestOptions{
+			Params: params,
+		})
 
-	body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return nil, err
+		}
 
-	if err != nil {
-		return nil, err
-	}
+		body, err := ioutil.ReadAll(res.Body)
+
+		if err != nil {
+			return nil, err
+		}
 
-	blacklistResponse := BlacklistResponse{}
+		blacklistResponse := BlacklistResponse{}
 
-	err = json.Unmarshal(body, &blacklistResponse)
+		err = json.Unmarshal(body, &blacklistResponse)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &blacklistResponse, nil
+	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &blacklistResponse, nil
+	return value.(*BlacklistResponse), nil
 }
@@ -0,0 +1,458 @@
+package abuseipdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// BlacklistSet is an in-memory set of blacklisted IP addresses and networks, optimised for fast membership checks.
+// Single addresses are looked up in constant time, and networks added with AddNetwork are matched by longest prefix.
+// A BlacklistSet is safe for concurrent use.
+// Use NewBlacklistSet or ReadBlacklistSet to initialise a new set.
+type BlacklistSet struct {
+	mu          sync.RWMutex
+	generatedAt time.Time
+	ipv4        map[uint32]blacklistValue
+	ipv6        map[[16]byte]blacklistValue
+	networks4   *prefixNode
+	networks6   *prefixNode
+	networks    int
+	nodes       int
+}
+
+// The sizes in bytes of blacklistValue and prefixNode on 64-bit platforms, used to estimate memory usage.
+const (
+	blacklistValueSize = 8
+	prefixNodeSize     = 32
+)
+
+// blacklistValue is the compact form of a blacklist entry, storing the time of the last report as seconds since the epoch.
+type blacklistValue struct {
+	lastReportedAt uint32
+	score          uint8
+}
+
+// BlacklistMatch represents the blacklist entry found for an IP address.
+type BlacklistMatch struct {
+	AbuseConfidenceScore int
+	LastReportedAt       time.Time
+	// Network is set to the matching network in CIDR notation if the address was matched by a network
+	// added with AddNetwork, rather than being listed individually.
+	Network string
+}
+
+// BlacklistSetStats represents the number of entries in a BlacklistSet, and an estimate of the memory they use.
+type BlacklistSetStats struct {
+	IPv4     int
+	IPv6     int
+	Networks int
+	// Bytes is an estimate of the memory used by the entries, excluding the overhead of the underlying maps.
+	Bytes int
+}
+
+type prefixNode struct {
+	children [2]*prefixNode
+	value    *blacklistValue
+	prefix   int
+}
+
+// NewBlacklistSet initialises a new set containing the IP addresses in the response provided, which may be nil.
+func NewBlacklistSet(response *BlacklistResponse) *BlacklistSet {
+	set := BlacklistSet{
+		ipv4:      make(map[uint32]blacklistValue),
+		ipv6:      make(map[[16]byte]blacklistValue),
+		networks4: &prefixNode{},
+		networks6: &prefixNode{},
+	}
+
+	if response != nil {
+		set.generatedAt = response.Meta.GeneratedAt
+
+		for _, entry := range response.Data {
+			// Entries which can't be parsed are skipped, as there's nothing useful to store for them.
+			_ = set.Add(entry.IPAddress, entry.AbuseConfidenceScore, entry.LastReportedAt)
+		}
+	}
+
+	return &set
+}
+
+// ReadBlacklistSet initialises a new set from a blacklist read from r, without holding the whole response in memory.
+// Both the JSON and plaintext formats returned by the Blacklist endpoint are accepted.
+// Scores and report times aren't included in the plaintext format, so are left empty.
+func ReadBlacklistSet(r io.Reader) (*BlacklistSet, error) {
+	set := NewBlacklistSet(nil)
+	reader := bufio.NewReader(r)
+
+	for {
+		b, err := reader.Peek(1)
+
+		if err == io.EOF {
+			return set, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if b[0] == '{' {
+			return set, set.readJSON(reader)
+		}
+
+		if !unicode.IsSpace(rune(b[0])) {
+			return set, set.readPlaintext(reader)
+		}
+
+		_, _ = reader.ReadByte()
+	}
+}
+
+func (s *BlacklistSet) readPlaintext(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			continue
+		}
+
+		err := s.Add(line, 0, time.Time{})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (s *BlacklistSet) readJSON(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+
+	err := expectDelim(decoder, '{')
+
+	if err != nil {
+		return err
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case "meta":
+			meta := struct {
+				GeneratedAt time.Time `json:"generatedAt"`
+			}{}
+
+			err = decoder.Decode(&meta)
+
+			if err != nil {
+				return err
+			}
+
+			s.mu.Lock()
+			s.generatedAt = meta.GeneratedAt
+			s.mu.Unlock()
+		case "data":
+			err = expectDelim(decoder, '[')
+
+			if err != nil {
+				return err
+			}
+
+			for decoder.More() {
+				entry := BlacklistEntry{}
+
+				err = decoder.Decode(&entry)
+
+				if err != nil {
+					return err
+				}
+
+				_ = s.Add(entry.IPAddress, entry.AbuseConfidenceScore, entry.LastReportedAt)
+			}
+
+			err = expectDelim(decoder, ']')
+
+			if err != nil {
+				return err
+			}
+		default:
+			err = decoder.Decode(&json.RawMessage{})
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return expectDelim(decoder, '}')
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+
+	if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return fmt.Errorf("abuseipdb: invalid blacklist, expected %q but found %v", delim, token)
+	}
+
+	return nil
+}
+
+// GeneratedAt returns the time the blacklist was generated, taken from the meta.generatedAt field of the response.
+func (s *BlacklistSet) GeneratedAt() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.generatedAt
+}
+
+// Add adds a single IP address (either v4 or v6) to the set, replacing any existing entry for it.
+func (s *BlacklistSet) Add(ipAddress string, score int, lastReportedAt time.Time) error {
+	ip := net.ParseIP(strings.TrimSpace(ipAddress))
+
+	if ip == nil {
+		return InvalidAddressError{
+			Address: ipAddress,
+			Reason:  ReasonMalformed,
+		}
+	}
+
+	value := newBlacklistValue(score, lastReportedAt)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ip4 := ip.To4(); ip4 != nil {
+		s.ipv4[binary.BigEndian.Uint32(ip4)] = value
+	} else {
+		key := [16]byte{}
+		copy(key[:], ip)
+		s.ipv6[key] = value
+	}
+
+	return nil
+}
+
+// AddNetwork adds a network (either v4 or v6), denoted with CIDR notation, to the set.
+// Every address in the network is matched by Contains and Lookup, unless it is listed individually.
+// Where networks overlap, the most specific network is used.
+func (s *BlacklistSet) AddNetwork(network string, score int) error {
+	ipNet, err := parseCIDR(network)
+
+	if err != nil {
+		return err
+	}
+
+	ones, _ := ipNet.Mask.Size()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.insertNetwork(ipNet.IP, ones, newBlacklistValue(score, time.Time{}))
+
+	return nil
+}
+
+// insertNetwork adds a network to the prefix trie for its address family. The caller must hold the write lock.
+func (s *BlacklistSet) insertNetwork(ip net.IP, ones int, value blacklistValue) {
+	node := s.networks6
+
+	if len(ip) == net.IPv4len {
+		node = s.networks4
+	}
+
+	for i := 0; i < ones; i++ {
+		bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+
+		if node.children[bit] == nil {
+			node.children[bit] = &prefixNode{}
+			s.nodes++
+		}
+
+		node = node.children[bit]
+	}
+
+	if node.value == nil {
+		s.networks++
+	}
+
+	node.value = &value
+	node.prefix = ones
+}
+
+// Contains reports whether an IP address is in the set, either individually or as part of a network.
+func (s *BlacklistSet) Contains(ip net.IP) bool {
+	_, ok := s.Lookup(ip)
+
+	return ok
+}
+
+// Lookup returns the blacklist entry for an IP address, if it is in the set.
+func (s *BlacklistSet) Lookup(ip net.IP) (BlacklistMatch, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	node := s.networks6
+
+	if ip4 := ip.To4(); ip4 != nil {
+		if value, ok := s.ipv4[binary.BigEndian.Uint32(ip4)]; ok {
+			return value.match(), true
+		}
+
+		ip = ip4
+		node = s.networks4
+	} else if len(ip) == net.IPv6len {
+		key := [16]byte{}
+		copy(key[:], ip)
+
+		if value, ok := s.ipv6[key]; ok {
+			return value.match(), true
+		}
+	} else {
+		return BlacklistMatch{}, false
+	}
+
+	var found *prefixNode
+
+	for i := 0; node != nil; i++ {
+		if node.value != nil {
+			found = node
+		}
+
+		if i == len(ip)*8 {
+			break
+		}
+
+		node = node.children[(ip[i/8]>>(7-uint(i%8)))&1]
+	}
+
+	if found == nil {
+		return BlacklistMatch{}, false
+	}
+
+	match := found.value.match()
+	mask := net.CIDRMask(found.prefix, len(ip)*8)
+	match.Network = (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String()
+
+	return match, true
+}
+
+// Response returns the addresses in the set as a BlacklistResponse, ordered as the Blacklist endpoint orders them:
+// by abuse confidence score and then by the time of the last report, both descending.
+// Networks added with AddNetwork aren't included.
+func (s *BlacklistSet) Response() *BlacklistResponse {
+	s.mu.RLock()
+
+	response := BlacklistResponse{}
+	response.Meta.GeneratedAt = s.generatedAt
+	response.Data = make([]BlacklistEntry, 0, len(s.ipv4)+len(s.ipv6))
+
+	ip := make(net.IP, net.IPv4len)
+
+	for key, value := range s.ipv4 {
+		binary.BigEndian.PutUint32(ip, key)
+		response.Data = append(response.Data, value.entry(ip.String()))
+	}
+
+	for key, value := range s.ipv6 {
+		response.Data = append(response.Data, value.entry(net.IP(key[:]).String()))
+	}
+
+	s.mu.RUnlock()
+
+	sortEntries(response.Data)
+	sort.SliceStable(response.Data, func(i, j int) bool {
+		a, b := response.Data[i], response.Data[j]
+
+		if a.AbuseConfidenceScore != b.AbuseConfidenceScore {
+			return a.AbuseConfidenceScore > b.AbuseConfidenceScore
+		}
+
+		return a.LastReportedAt.After(b.LastReportedAt)
+	})
+
+	return &response
+}
+
+// Len returns the number of addresses and networks in the set.
+func (s *BlacklistSet) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.ipv4) + len(s.ipv6) + s.networks
+}
+
+// Stats returns the number of entries in the set, and an estimate of the memory they use.
+func (s *BlacklistSet) Stats() BlacklistSetStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return BlacklistSetStats{
+		IPv4:     len(s.ipv4),
+		IPv6:     len(s.ipv6),
+		Networks: s.networks,
+		Bytes: len(s.ipv4)*(4+blacklistValueSize) +
+			len(s.ipv6)*(16+blacklistValueSize) +
+			s.nodes*prefixNodeSize +
+			s.networks*blacklistValueSize,
+	}
+}
+
+func newBlacklistValue(score int, lastReportedAt time.Time) blacklistValue {
+	value := blacklistValue{}
+
+	if score > 0 {
+		value.score = uint8(score)
+	}
+
+	if score > 100 {
+		value.score = 100
+	}
+
+	if !lastReportedAt.IsZero() && lastReportedAt.Unix() > 0 {
+		value.lastReportedAt = uint32(lastReportedAt.Unix())
+	}
+
+	return value
+}
+
+func (v blacklistValue) entry(ipAddress string) BlacklistEntry {
+	match := v.match()
+
+	return BlacklistEntry{
+		IPAddress:            ipAddress,
+		AbuseConfidenceScore: match.AbuseConfidenceScore,
+		LastReportedAt:       match.LastReportedAt,
+	}
+}
+
+func (v blacklistValue) match() BlacklistMatch {
+	match := BlacklistMatch{
+		AbuseConfidenceScore: int(v.score),
+	}
+
+	if v.lastReportedAt != 0 {
+		match.LastReportedAt = time.Unix(int64(v.lastReportedAt), 0).UTC()
+	}
+
+	return match
+}
@@ -0,0 +1,130 @@
+package abuseipdb
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBlacklistJSON = `{"meta":{"generatedAt":"2021-08-18T10:00:00+00:00"},"data":[
+	{"ipAddress":"1.1.1.1","abuseConfidenceScore":100,"lastReportedAt":"2021-08-18T09:00:00+00:00"},
+	{"ipAddress":"2606:4700::1111","abuseConfidenceScore":75,"lastReportedAt":"2021-08-17T09:00:00+00:00"},
+	{"ipAddress":"8.8.8.8","abuseConfidenceScore":50,"lastReportedAt":"2021-08-16T09:00:00+00:00"}
+]}`
+
+func TestReadBlacklistSet(t *testing.T) {
+	set, err := ReadBlacklistSet(strings.NewReader(testBlacklistJSON))
+
+	if err != nil {
+		t.Logf("ReadBlacklistSet: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if !set.GeneratedAt().Equal(time.Date(2021, 8, 18, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("BlacklistSet.GeneratedAt: expected 2021-08-18 10:00:00, got %v", set.GeneratedAt())
+	}
+
+	if set.Len() != 3 {
+		t.Errorf("BlacklistSet.Len: expected 3, got %d", set.Len())
+	}
+
+	match, ok := set.Lookup(net.ParseIP("2606:4700::1111"))
+
+	if !ok || match.AbuseConfidenceScore != 75 || !match.LastReportedAt.Equal(time.Date(2021, 8, 17, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("BlacklistSet.Lookup: expected score 75 reported at 2021-08-17 09:00:00, got %+v", match)
+	}
+
+	set, err = ReadBlacklistSet(strings.NewReader("\n1.1.1.1\n8.8.8.8\n"))
+
+	if err != nil {
+		t.Logf("ReadBlacklistSet: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if !set.Contains(net.ParseIP("8.8.8.8")) || set.Len() != 2 {
+		t.Errorf("ReadBlacklistSet: expected plaintext blacklist to contain 2 addresses including 8.8.8.8, got %d", set.Len())
+	}
+
+	_, err = ReadBlacklistSet(strings.NewReader(`{"data":{}}`))
+
+	if err == nil {
+		t.Errorf("ReadBlacklistSet: expected err to be non-nil for an invalid blacklist")
+	}
+}
+
+func TestBlacklistSet(t *testing.T) {
+	response := BlacklistResponse{}
+	response.Data = []BlacklistEntry{
+		{IPAddress: "1.1.1.1", AbuseConfidenceScore: 100},
+		{IPAddress: "10.1.2.3", AbuseConfidenceScore: 90},
+	}
+
+	set := NewBlacklistSet(&response)
+
+	err := set.AddNetwork("10.0.0.0/8", 25)
+
+	if err != nil {
+		t.Logf("BlacklistSet.AddNetwork: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	_ = set.AddNetwork("10.1.0.0/16", 50)
+	_ = set.AddNetwork("2001:db8::/32", 60)
+
+	tests := []struct {
+		ip      string
+		ok      bool
+		score   int
+		network string
+	}{
+		{"1.1.1.1", true, 100, ""},
+		{"::ffff:1.1.1.1", true, 100, ""},
+		{"1.1.1.2", false, 0, ""},
+		{"10.1.2.3", true, 90, ""},
+		{"10.1.2.4", true, 50, "10.1.0.0/16"},
+		{"10.2.0.1", true, 25, "10.0.0.0/8"},
+		{"2001:db8::1", true, 60, "2001:db8::/32"},
+		{"2001:db9::1", false, 0, ""},
+	}
+
+	for _, test := range tests {
+		match, ok := set.Lookup(net.ParseIP(test.ip))
+
+		if ok != test.ok || match.AbuseConfidenceScore != test.score || match.Network != test.network {
+			t.Errorf(`BlacklistSet.Lookup: expected %t, %d, "%s" for %s, got %t, %d, "%s"`, test.ok, test.score, test.network, test.ip, ok, match.AbuseConfidenceScore, match.Network)
+		}
+	}
+
+	stats := set.Stats()
+
+	if stats.IPv4 != 2 || stats.IPv6 != 0 || stats.Networks != 3 || stats.Bytes == 0 {
+		t.Errorf("BlacklistSet.Stats: expected 2 IPv4 addresses and 3 networks, got %+v", stats)
+	}
+
+	if set.Contains(nil) {
+		t.Errorf("BlacklistSet.Contains: expected nil IP not to be contained")
+	}
+}
+
+func TestBlacklistSet_Response(t *testing.T) {
+	set, _ := ReadBlacklistSet(strings.NewReader(testBlacklistJSON))
+	_ = set.Add("9.9.9.9", 75, time.Date(2021, 8, 18, 9, 0, 0, 0, time.UTC))
+	_ = set.AddNetwork("192.0.2.0/24", 100)
+
+	response := set.Response()
+
+	if !response.Meta.GeneratedAt.Equal(set.GeneratedAt()) {
+		t.Errorf("BlacklistSet.Response: expected generatedAt %v, got %v", set.GeneratedAt(), response.Meta.GeneratedAt)
+	}
+
+	var order []string
+
+	for _, entry := range response.Data {
+		order = append(order, entry.IPAddress)
+	}
+
+	if strings.Join(order, " ") != "1.1.1.1 9.9.9.9 2606:4700::1111 8.8.8.8" {
+		t.Errorf("BlacklistSet.Response: expected entries ordered by score and last report, got %v", order)
+	}
+}
@@ -0,0 +1,146 @@
+package abuseipdb
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// CacheEntry represents a cached response from the AbuseIPDB API.
+type CacheEntry struct {
+	Check      *CheckResponse      `json:"check,omitempty"`
+	CheckBlock *CheckBlockResponse `json:"checkBlock,omitempty"`
+	FetchedAt  time.Time           `json:"fetchedAt"`
+	// StaleAt is the time after which the entry may only be used if a fresh response can't be fetched.
+	// If it isn't set, the entry is fresh until it expires.
+	StaleAt   time.Time `json:"staleAt,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	// Partial is true if Check was built from a CheckBlock or Blacklist response rather than fetched with Check,
+	// in which case only the IP address, abuse confidence score, country code, total reports and time of the
+	// most recent report may be set.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// Expired reports whether the entry has passed its expiry time.
+// Entries without an expiry time never expire.
+func (e CacheEntry) Expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// Stale reports whether the entry has passed the time after which it should be refreshed.
+func (e CacheEntry) Stale(now time.Time) bool {
+	if e.StaleAt.IsZero() {
+		return e.Expired(now)
+	}
+
+	return !now.Before(e.StaleAt)
+}
+
+// Cache stores responses from the AbuseIPDB API, so that repeated lookups don't use up the daily quota.
+// Implementations must be safe for concurrent use, and must not return entries which have expired.
+type Cache interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, entry CacheEntry)
+	Delete(key string)
+}
+
+// MemoryCache is an in-memory Cache which holds a bounded number of entries,
+// evicting the least recently used entry when full.
+// Use NewMemoryCache to initialise a new cache.
+type MemoryCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewMemoryCache initialises a new in-memory cache holding at most size entries.
+func NewMemoryCache(size int) *MemoryCache {
+	if size < 1 {
+		size = 1
+	}
+
+	cache := MemoryCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+
+	return &cache
+}
+
+// Get returns the entry stored for key, if present and not expired.
+func (m *MemoryCache) Get(key string) (CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+
+	if !ok {
+		return CacheEntry{}, false
+	}
+
+	item := element.Value.(*memoryCacheItem)
+
+	if item.entry.Expired(m.now()) {
+		m.removeElement(element)
+
+		return CacheEntry{}, false
+	}
+
+	m.order.MoveToFront(element)
+
+	return item.entry, true
+}
+
+// Set stores an entry for key, evicting the least recently used entry if the cache is full.
+func (m *MemoryCache) Set(key string, entry CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		element.Value.(*memoryCacheItem).entry = entry
+		m.order.MoveToFront(element)
+
+		return
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryCacheItem{
+		key:   key,
+		entry: entry,
+	})
+
+	for m.order.Len() > m.size {
+		m.removeElement(m.order.Back())
+	}
+}
+
+// Delete removes the entry stored for key.
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		m.removeElement(element)
+	}
+}
+
+// Len returns the number of entries in the cache, including any which have expired but not yet been evicted.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.order.Len()
+}
+
+func (m *MemoryCache) removeElement(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.entries, element.Value.(*memoryCacheItem).key)
+}
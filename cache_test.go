@@ -0,0 +1,56 @@
+package abuseipdb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryCache(t *testing.T) {
+	cache := NewMemoryCache(2)
+	now := time.Now()
+	cache.now = func() time.Time {
+		return now
+	}
+
+	cache.Set("a", CacheEntry{Check: &CheckResponse{}, ExpiresAt: now.Add(time.Minute)})
+	cache.Set("b", CacheEntry{Check: &CheckResponse{}, ExpiresAt: now.Add(time.Minute)})
+
+	// Reading "a" makes "b" the least recently used entry.
+	if _, ok := cache.Get("a"); !ok {
+		t.Errorf(`MemoryCache.Get: expected entry for "a"`)
+	}
+
+	cache.Set("c", CacheEntry{Check: &CheckResponse{}, ExpiresAt: now.Add(time.Minute)})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf(`MemoryCache.Get: expected "b" to have been evicted`)
+	}
+
+	if cache.Len() != 2 {
+		t.Errorf("MemoryCache.Len: expected 2, got %d", cache.Len())
+	}
+
+	now = now.Add(time.Minute)
+
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf(`MemoryCache.Get: expected "a" to have expired`)
+	}
+
+	cache.Delete("c")
+
+	if cache.Len() != 0 {
+		t.Errorf("MemoryCache.Len: expected 0, got %d", cache.Len())
+	}
+}
+
+func TestCacheEntry_Expired(t *testing.T) {
+	now := time.Now()
+
+	if (CacheEntry{}).Expired(now) {
+		t.Errorf("CacheEntry.Expired: expected entry without an expiry time not to expire")
+	}
+
+	if !(CacheEntry{ExpiresAt: now}).Expired(now) {
+		t.Errorf("CacheEntry.Expired: expected entry to expire at its expiry time")
+	}
+}
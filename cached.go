@@ -0,0 +1,528 @@
+package abuseipdb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CachedClient wraps a Client, storing the responses from Check and CheckBlock in a Cache.
+// All other methods are passed through to the underlying Client.
+// Use NewCachedClient to initialise a new cached client.
+type CachedClient struct {
+	// hits and misses are accessed atomically, so must remain 64-bit aligned.
+	hits   uint64
+	misses uint64
+
+	*Client
+	cache                Cache
+	ttl                  func(*CheckResponse) time.Duration
+	blockTTL             time.Duration
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+	warm                 bool
+	now                  func() time.Time
+
+	mu         sync.Mutex
+	refreshing map[string]bool
+	retryAt    time.Time
+}
+
+// LookupResult represents the response for an IP checked using a CachedClient, along with details of where it came from.
+type LookupResult struct {
+	Response *CheckResponse
+	// Cached is true if the response was served from the cache rather than the API.
+	Cached bool
+	// Stale is true if the response is older than its TTL, and was served because the API was unavailable,
+	// or whilst it is refreshed in the background.
+	Stale bool
+	// Age is how long ago the response was fetched from the API.
+	Age time.Duration
+	// Partial is true if the response was built from a CheckBlock or Blacklist response, see WarmCache.
+	Partial bool
+}
+
+// CacheStats represents the number of lookups made by a CachedClient which were served from its cache.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// HitRatio returns the proportion of lookups which were served from the cache, between 0 and 1.
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+type cacheConfig struct {
+	ttl                  func(*CheckResponse) time.Duration
+	blockTTL             time.Duration
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+	warm                 bool
+}
+
+var defaultCacheConfig = cacheConfig{
+	ttl: func(*CheckResponse) time.Duration {
+		return time.Hour
+	},
+	blockTTL: time.Hour,
+}
+
+// CacheOption sets an optional parameter when creating a CachedClient.
+type CacheOption func(*cacheConfig)
+
+// CacheTTL returns a CacheOption that sets how long responses are cached for.
+// The default value is 1 hour.
+func CacheTTL(ttl time.Duration) CacheOption {
+	return func(config *cacheConfig) {
+		config.ttl = func(*CheckResponse) time.Duration {
+			return ttl
+		}
+		config.blockTTL = ttl
+	}
+}
+
+// CacheTTLFunc returns a CacheOption that sets a function used to decide how long each response is cached for.
+// This can be used to keep IPs with a high abuse confidence score for longer than those with a low score.
+// Responses for which the function returns a duration less than or equal to zero are not cached.
+func CacheTTLFunc(ttl func(response *CheckResponse) time.Duration) CacheOption {
+	return func(config *cacheConfig) {
+		config.ttl = ttl
+	}
+}
+
+// StaleWhileRevalidate returns a CacheOption that allows responses to be served for up to the duration provided
+// after their TTL has passed. A stale response is returned immediately, and refreshed in the background.
+// By default, responses are never served once their TTL has passed.
+func StaleWhileRevalidate(maxStaleness time.Duration) CacheOption {
+	return func(config *cacheConfig) {
+		config.staleWhileRevalidate = maxStaleness
+	}
+}
+
+// StaleIfError returns a CacheOption that allows responses to be served for up to the duration provided
+// after their TTL has passed, if a fresh response can't be fetched because the API is unreachable,
+// returns a server error or the rate limit has been exceeded.
+// By default, responses are never served once their TTL has passed.
+func StaleIfError(maxStaleness time.Duration) CacheOption {
+	return func(config *cacheConfig) {
+		config.staleIfError = maxStaleness
+	}
+}
+
+// WarmCache returns a CacheOption that sets whether the IPs in responses from CheckBlock and Blacklist are stored as
+// partial entries for Check. Partial entries only hold the abuse confidence score, country code, number of reports and
+// time of the most recent report, and are used to answer calls to Check and Lookup which disable the Verbose option,
+// regardless of MaxAgeInDays. Verbose checks always fetch the full details.
+// This option is disabled by default.
+func WarmCache(enabled bool) CacheOption {
+	return func(config *cacheConfig) {
+		config.warm = enabled
+	}
+}
+
+// NewCachedClient initialises a new cached client, which stores responses in the cache provided.
+func NewCachedClient(client *Client, cache Cache, options ...CacheOption) *CachedClient {
+	config := defaultCacheConfig
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	cachedClient := CachedClient{
+		Client:               client,
+		cache:                cache,
+		ttl:                  config.ttl,
+		blockTTL:             config.blockTTL,
+		staleWhileRevalidate: config.staleWhileRevalidate,
+		staleIfError:         config.staleIfError,
+		warm:                 config.warm,
+		now:                  time.Now,
+		refreshing:           make(map[string]bool),
+	}
+
+	return &cachedClient
+}
+
+// Check will return the stored information about the IP provided (either v4 or v6),
+// using the cached response if the same IP has been checked with the same options recently.
+// Cached responses are shared between callers, so must not be modified.
+// Use Lookup to find out whether the response was served from the cache, and whether it is stale.
+func (c *CachedClient) Check(ipAddress string, options ...CheckOption) (*CheckResponse, error) {
+	result, err := c.Lookup(ipAddress, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Response, nil
+}
+
+// Lookup will return the stored information about the IP provided (either v4 or v6) in the same way as Check,
+// along with whether the response was served from the cache and how old it is.
+func (c *CachedClient) Lookup(ipAddress string, options ...CheckOption) (*LookupResult, error) {
+	config := defaultCheckConfig
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	ipAddress, err := c.Client.normalizeIP(ipAddress)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key := checkKey(ipAddress, config)
+	now := c.now()
+
+	if fresh, ok := c.freshEntry(ipAddress, config, now); ok {
+		atomic.AddUint64(&c.hits, 1)
+
+		return c.lookupResult(fresh, now), nil
+	}
+
+	entry, ok := c.cache.Get(key)
+	ok = ok && entry.Check != nil
+
+	if ok && now.Before(entry.StaleAt.Add(c.staleWhileRevalidate)) {
+		atomic.AddUint64(&c.hits, 1)
+		c.revalidate(key, ipAddress, options)
+
+		return c.lookupResult(entry, now), nil
+	}
+
+	// Whilst rate limited, there's no point making a request if a stale response can be served instead.
+	if ok && c.rateLimited(now) {
+		atomic.AddUint64(&c.hits, 1)
+
+		return c.lookupResult(entry, now), nil
+	}
+
+	atomic.AddUint64(&c.misses, 1)
+
+	checkResponse, err := c.fetch(key, ipAddress, options)
+
+	if err != nil {
+		if ok && unavailable(err) {
+			return c.lookupResult(entry, now), nil
+		}
+
+		return nil, err
+	}
+
+	return &LookupResult{
+		Response: checkResponse,
+	}, nil
+}
+
+// freshEntry returns the cached response for a normalised IP, if there is one which hasn't passed its TTL.
+func (c *CachedClient) freshEntry(ipAddress string, config checkConfig, now time.Time) (CacheEntry, bool) {
+	entry, ok := c.cache.Get(checkKey(ipAddress, config))
+
+	if ok && entry.Check != nil {
+		return entry, !entry.Stale(now)
+	}
+
+	// Partial entries carry enough information for checks which don't need the verbose details.
+	if !config.verbose {
+		if partial, found := c.cache.Get(partialCheckKey(ipAddress)); found && partial.Check != nil && !partial.Stale(now) {
+			return partial, true
+		}
+	}
+
+	return CacheEntry{}, false
+}
+
+// Assess returns the abuse data for each of the IPs provided in the same way as Client.Assess,
+// using cached responses where possible. See ExecutePlan for details.
+func (c *CachedClient) Assess(ipAddresses []string, options ...CheckOption) ([]Assessment, error) {
+	plan, err := c.PlanLookups(ipAddresses, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ExecutePlan(plan, options...)
+}
+
+// ExecutePlan makes the requests in a LookupPlan in the same way as Client.ExecutePlan, using cached responses
+// where possible. IPs with a fresh response in the cache are removed from the plan before it is carried out,
+// and a block left with fewer IPs than the BlockThreshold is replaced with checks of the IPs remaining.
+// Responses from the requests which are made are stored in the cache.
+func (c *CachedClient) ExecutePlan(plan *LookupPlan, options ...CheckOption) ([]Assessment, error) {
+	config := defaultCheckConfig
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	threshold, err := blockThreshold(config, plan.plan)
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := c.now()
+	cached := make(map[string]Assessment)
+	remaining := LookupPlan{
+		Invalid: plan.Invalid,
+		order:   plan.order,
+		plan:    plan.plan,
+	}
+
+	uncached := func(ipAddresses []string) []string {
+		var ips []string
+
+		for _, ipAddress := range ipAddresses {
+			if entry, ok := c.freshEntry(ipAddress, config, now); ok {
+				atomic.AddUint64(&c.hits, 1)
+				cached[ipAddress] = checkAssessment(ipAddress, entry.Check, nil)
+			} else {
+				ips = append(ips, ipAddress)
+			}
+		}
+
+		return ips
+	}
+
+	for _, block := range plan.Blocks {
+		ips := uncached(block.IPAddresses)
+
+		if len(ips) >= threshold {
+			remaining.Blocks = append(remaining.Blocks, PlannedBlock{Network: block.Network, IPAddresses: ips})
+		} else {
+			remaining.Checks = append(remaining.Checks, ips...)
+		}
+	}
+
+	remaining.Checks = append(remaining.Checks, uncached(plan.Checks)...)
+
+	assessments, err := executePlan(c, &remaining, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, ipAddress := range remaining.order {
+		if assessment, ok := cached[ipAddress]; ok {
+			assessments[i] = assessment
+		}
+	}
+
+	return assessments, nil
+}
+
+// CheckMany checks each of the IPs provided in the same way as Client.CheckMany, using cached responses where
+// possible, so only IPs which haven't been checked recently use the API's quota.
+func (c *CachedClient) CheckMany(ctx context.Context, ipAddresses []string, options ...CheckOption) <-chan CheckManyResult {
+	return c.Client.checkStream(ctx, c, sendIPs(ctx, ipAddresses), options)
+}
+
+// CheckStream checks each of the IPs received from the channel provided in the same way as Client.CheckStream,
+// using cached responses where possible, so only IPs which haven't been checked recently use the API's quota.
+func (c *CachedClient) CheckStream(ctx context.Context, ipAddresses <-chan string, options ...CheckOption) <-chan CheckManyResult {
+	return c.Client.checkStream(ctx, c, ipAddresses, options)
+}
+
+// fetch checks an IP using the underlying client, and stores the response in the cache.
+func (c *CachedClient) fetch(key string, ipAddress string, options []CheckOption) (*CheckResponse, error) {
+	checkResponse, err := c.Client.Check(ipAddress, options...)
+
+	if err != nil {
+		if requestError, ok := err.(RequestError); ok && requestError.StatusCode == http.StatusTooManyRequests {
+			c.mu.Lock()
+			c.retryAt = c.now().Add(requestError.RetryAfter)
+			c.mu.Unlock()
+		}
+
+		return nil, err
+	}
+
+	if ttl := c.ttl(checkResponse); ttl > 0 {
+		now := c.now()
+		staleness := c.staleWhileRevalidate
+
+		if c.staleIfError > staleness {
+			staleness = c.staleIfError
+		}
+
+		c.cache.Set(key, CacheEntry{
+			Check:     checkResponse,
+			FetchedAt: now,
+			StaleAt:   now.Add(ttl),
+			ExpiresAt: now.Add(ttl + staleness),
+		})
+	}
+
+	return checkResponse, nil
+}
+
+// revalidate refreshes a stale entry in the background, unless it is already being refreshed
+// or the rate limit has been exceeded.
+func (c *CachedClient) revalidate(key string, ipAddress string, options []CheckOption) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.refreshing[key] || c.now().Before(c.retryAt) {
+		return
+	}
+
+	c.refreshing[key] = true
+
+	go func() {
+		_, _ = c.fetch(key, ipAddress, options)
+
+		c.mu.Lock()
+		delete(c.refreshing, key)
+		c.mu.Unlock()
+	}()
+}
+
+func (c *CachedClient) rateLimited(now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return now.Before(c.retryAt)
+}
+
+func (c *CachedClient) lookupResult(entry CacheEntry, now time.Time) *LookupResult {
+	return &LookupResult{
+		Response: entry.Check,
+		Cached:   true,
+		Stale:    entry.Stale(now),
+		Age:      now.Sub(entry.FetchedAt),
+		Partial:  entry.Partial,
+	}
+}
+
+// unavailable reports whether an error was caused by the API being unreachable, failing or rate limited,
+// rather than by the request itself.
+func unavailable(err error) bool {
+	if requestError, ok := err.(RequestError); ok {
+		return requestError.StatusCode == http.StatusTooManyRequests || requestError.StatusCode >= 500
+	}
+
+	_, ok := err.(net.Error)
+
+	return ok
+}
+
+// CheckBlock will return the stored information about the subnet (either v4 or v6) provided, denoted with CIDR notation,
+// using the cached response if the same subnet has been checked with the same options recently.
+// Responses are cached for the TTL configured with CacheTTL, or one hour if a CacheTTLFunc is used.
+// Cached responses are shared between callers, so must not be modified.
+func (c *CachedClient) CheckBlock(subnet string, options ...CheckOption) (*CheckBlockResponse, error) {
+	config := defaultCheckBlockConfig
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	key := subnet
+
+	if network, err := parseCIDR(subnet); err == nil {
+		key = network.String()
+	}
+
+	key = fmt.Sprintf("check-block:%s:%d", key, config.maxAgeInDays)
+
+	if entry, ok := c.cache.Get(key); ok && entry.CheckBlock != nil {
+		atomic.AddUint64(&c.hits, 1)
+
+		return entry.CheckBlock, nil
+	}
+
+	atomic.AddUint64(&c.misses, 1)
+
+	checkBlockResponse, err := c.Client.CheckBlock(subnet, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if c.blockTTL > 0 {
+		now := c.now()
+
+		c.cache.Set(key, CacheEntry{
+			CheckBlock: checkBlockResponse,
+			FetchedAt:  now,
+			ExpiresAt:  now.Add(c.blockTTL),
+		})
+	}
+
+	if c.warm {
+		for _, reported := range checkBlockResponse.Data.ReportedAddress {
+			checkResponse := &CheckResponse{}
+			checkResponse.Data.IPAddress = canonicalIP(reported.IPAddress)
+			checkResponse.Data.AbuseConfidenceScore = reported.AbuseConfidenceScore
+			checkResponse.Data.CountryCode = reported.CountryCode
+			checkResponse.Data.TotalReports = reported.NumReports
+			checkResponse.Data.LastReportedAt = reported.MostRecentReport
+
+			c.storePartial(checkResponse)
+		}
+	}
+
+	return checkBlockResponse, nil
+}
+
+// Blacklist will return a list of the most reported IP addresses.
+// If WarmCache is enabled, each IP is stored as a partial entry for Check. The Blacklist response itself isn't cached.
+func (c *CachedClient) Blacklist(options ...BlacklistOption) (*BlacklistResponse, error) {
+	blacklistResponse, err := c.Client.Blacklist(options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if c.warm {
+		for _, listed := range blacklistResponse.Data {
+			checkResponse := &CheckResponse{}
+			checkResponse.Data.IPAddress = canonicalIP(listed.IPAddress)
+			checkResponse.Data.AbuseConfidenceScore = listed.AbuseConfidenceScore
+			checkResponse.Data.LastReportedAt = listed.LastReportedAt
+
+			c.storePartial(checkResponse)
+		}
+	}
+
+	return blacklistResponse, nil
+}
+
+func (c *CachedClient) storePartial(checkResponse *CheckResponse) {
+	ttl := c.ttl(checkResponse)
+
+	if ttl <= 0 {
+		return
+	}
+
+	now := c.now()
+
+	c.cache.Set(partialCheckKey(checkResponse.Data.IPAddress), CacheEntry{
+		Check:     checkResponse,
+		FetchedAt: now,
+		ExpiresAt: now.Add(ttl),
+		Partial:   true,
+	})
+}
+
+func partialCheckKey(ipAddress string) string {
+	return "check-partial:" + ipAddress
+}
+
+// Stats returns the number of cache hits and misses since the client was created.
+func (c *CachedClient) Stats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+	}
+}
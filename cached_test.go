@@ -0,0 +1,271 @@
+package abuseipdb
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCachedClient_Check(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		fmt.Fprintf(w, `{"data":{"ipAddress":"%s","abuseConfidenceScore":100}}`, r.URL.Query().Get("ipAddress"))
+	})
+
+	cache := NewMemoryCache(10)
+	cachedClient := NewCachedClient(client, cache, CacheTTLFunc(func(response *CheckResponse) time.Duration {
+		return time.Duration(response.Data.AbuseConfidenceScore) * time.Minute
+	}))
+
+	for i := 0; i < 3; i++ {
+		checkResponse, err := cachedClient.Check("1.1.1.1")
+
+		if err != nil {
+			t.Logf("CachedClient.Check: expected err to be nil, got %v", err)
+			t.FailNow()
+		}
+
+		if checkResponse.Data.IPAddress != "1.1.1.1" {
+			t.Errorf(`CachedClient.Check: expected IP address to be "1.1.1.1", got "%s"`, checkResponse.Data.IPAddress)
+		}
+	}
+
+	// The normalised form of the address shares a cache entry, but different options don't.
+	_, _ = cachedClient.Check("::ffff:1.1.1.1")
+	_, _ = cachedClient.Check("1.1.1.1", Verbose(false))
+
+	if requests != 2 {
+		t.Errorf("CachedClient.Check: expected 2 requests, got %d", requests)
+	}
+
+	stats := cachedClient.Stats()
+
+	if stats.Hits != 3 || stats.Misses != 2 {
+		t.Errorf("CachedClient.Stats: expected 3 hits and 2 misses, got %d hits and %d misses", stats.Hits, stats.Misses)
+	}
+
+	if stats.HitRatio() != 0.6 {
+		t.Errorf("CacheStats.HitRatio: expected 0.6, got %f", stats.HitRatio())
+	}
+
+	// A score of 100 is cached for 100 minutes.
+	cache.now = func() time.Time {
+		return time.Now().Add(101 * time.Minute)
+	}
+
+	_, _ = cachedClient.Check("1.1.1.1")
+
+	if requests != 3 {
+		t.Errorf("CachedClient.Check: expected expired entry to be fetched again, got %d requests", requests)
+	}
+}
+
+func TestCachedClient_CheckBlock(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		fmt.Fprint(w, `{"data":{"networkAddress":"1.1.1.0","netmask":"255.255.255.0","numPossibleHosts":254}}`)
+	})
+
+	cachedClient := NewCachedClient(client, NewMemoryCache(10))
+
+	for _, subnet := range []string{"1.1.1.0/24", "1.1.1.7/24"} {
+		checkBlockResponse, err := cachedClient.CheckBlock(subnet)
+
+		if err != nil {
+			t.Logf("CachedClient.CheckBlock: expected err to be nil, got %v", err)
+			t.FailNow()
+		}
+
+		if checkBlockResponse.Data.NumPossibleHosts != 254 {
+			t.Errorf("CachedClient.CheckBlock: expected number of possible hosts to be 254, got %d", checkBlockResponse.Data.NumPossibleHosts)
+		}
+	}
+
+	if requests != 1 {
+		t.Errorf("CachedClient.CheckBlock: expected 1 request, got %d", requests)
+	}
+}
+
+func TestCachedClient_LookupStale(t *testing.T) {
+	var requests, failing int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		if atomic.LoadInt32(&failing) == 1 {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"errors":[{"detail":"Daily rate limit of 1000 requests exceeded for this endpoint.","status":429}]}`)
+
+			return
+		}
+
+		fmt.Fprintf(w, `{"data":{"ipAddress":"%s","abuseConfidenceScore":100}}`, r.URL.Query().Get("ipAddress"))
+	})
+
+	now := time.Now()
+	clock := func() time.Time {
+		return now
+	}
+
+	cache := NewMemoryCache(10)
+	cache.now = clock
+	cachedClient := NewCachedClient(client, cache, CacheTTL(time.Minute), StaleIfError(time.Hour))
+	cachedClient.now = clock
+
+	result, err := cachedClient.Lookup("1.1.1.1")
+
+	if err != nil {
+		t.Logf("CachedClient.Lookup: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if result.Cached || result.Stale {
+		t.Errorf("CachedClient.Lookup: expected first result to be fetched from the API, got cached: %t, stale: %t", result.Cached, result.Stale)
+	}
+
+	atomic.StoreInt32(&failing, 1)
+	now = now.Add(10 * time.Minute)
+
+	result, err = cachedClient.Lookup("1.1.1.1")
+
+	if err != nil {
+		t.Logf("CachedClient.Lookup: expected stale result to be served, got %v", err)
+		t.FailNow()
+	}
+
+	if !result.Cached || !result.Stale || result.Age != 10*time.Minute {
+		t.Errorf("CachedClient.Lookup: expected cached stale result 10 minutes old, got cached: %t, stale: %t, age: %s", result.Cached, result.Stale, result.Age)
+	}
+
+	// Whilst rate limited, the stale result is served without making a request.
+	_, _ = cachedClient.Lookup("1.1.1.1")
+
+	if requests != 2 {
+		t.Errorf("CachedClient.Lookup: expected 2 requests, got %d", requests)
+	}
+
+	// Once the maximum staleness has passed, the error is returned.
+	now = now.Add(2 * time.Hour)
+
+	_, err = cachedClient.Lookup("1.1.1.1")
+
+	if requestError, ok := err.(RequestError); !ok || requestError.RetryAfter != time.Minute {
+		t.Errorf("CachedClient.Lookup: expected RequestError with a RetryAfter of 1 minute, got %v", err)
+	}
+}
+
+func TestCachedClient_LookupStaleWhileRevalidate(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		score := atomic.AddInt32(&requests, 1)
+
+		fmt.Fprintf(w, `{"data":{"ipAddress":"%s","abuseConfidenceScore":%d}}`, r.URL.Query().Get("ipAddress"), score)
+	})
+
+	now := time.Now()
+	clock := func() time.Time {
+		return now
+	}
+
+	cache := NewMemoryCache(10)
+	cache.now = clock
+	cachedClient := NewCachedClient(client, cache, CacheTTL(time.Minute), StaleWhileRevalidate(time.Hour))
+	cachedClient.now = clock
+
+	_, _ = cachedClient.Lookup("1.1.1.1")
+
+	now = now.Add(2 * time.Minute)
+
+	result, err := cachedClient.Lookup("1.1.1.1")
+
+	if err != nil {
+		t.Logf("CachedClient.Lookup: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if !result.Stale || result.Response.Data.AbuseConfidenceScore != 1 {
+		t.Errorf("CachedClient.Lookup: expected stale result with score 1, got stale: %t, score: %d", result.Stale, result.Response.Data.AbuseConfidenceScore)
+	}
+
+	for i := 0; i < 100; i++ {
+		if entry, ok := cache.Get(checkKey("1.1.1.1", defaultCheckConfig)); ok && entry.Check.Data.AbuseConfidenceScore == 2 {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	result, _ = cachedClient.Lookup("1.1.1.1")
+
+	if result.Stale || result.Response.Data.AbuseConfidenceScore != 2 {
+		t.Errorf("CachedClient.Lookup: expected refreshed result with score 2, got stale: %t, score: %d", result.Stale, result.Response.Data.AbuseConfidenceScore)
+	}
+}
+
+func TestCachedClient_WarmCache(t *testing.T) {
+	var checks int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/check":
+			atomic.AddInt32(&checks, 1)
+			fmt.Fprintf(w, `{"data":{"ipAddress":"%s","abuseConfidenceScore":60,"countryName":"Australia"}}`, r.URL.Query().Get("ipAddress"))
+		case "/check-block":
+			fmt.Fprint(w, `{"data":{"networkAddress":"1.1.1.0","reportedAddress":[{"ipAddress":"1.1.1.2","numReports":4,"abuseConfidenceScore":30,"countryCode":"AU"}]}}`)
+		case "/blacklist":
+			fmt.Fprint(w, `{"meta":{"generatedAt":"2021-08-18T10:00:00+00:00"},"data":[{"ipAddress":"8.8.8.8","abuseConfidenceScore":100,"lastReportedAt":"2021-08-18T09:00:00+00:00"}]}`)
+		}
+	})
+
+	cachedClient := NewCachedClient(client, NewMemoryCache(10), WarmCache(true))
+
+	_, err := cachedClient.CheckBlock("1.1.1.0/24")
+
+	if err != nil {
+		t.Logf("CachedClient.CheckBlock: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	_, err = cachedClient.Blacklist()
+
+	if err != nil {
+		t.Logf("CachedClient.Blacklist: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	result, err := cachedClient.Lookup("1.1.1.2", Verbose(false))
+
+	if err != nil || !result.Partial || result.Response.Data.AbuseConfidenceScore != 30 || result.Response.Data.TotalReports != 4 {
+		t.Errorf("CachedClient.Lookup: expected partial result for 1.1.1.2 with score 30 and 4 reports, got %+v (%v)", result, err)
+	}
+
+	result, err = cachedClient.Lookup("8.8.8.8", Verbose(false))
+
+	if err != nil || !result.Partial || result.Response.Data.AbuseConfidenceScore != 100 {
+		t.Errorf("CachedClient.Lookup: expected partial result for 8.8.8.8 with score 100, got %+v (%v)", result, err)
+	}
+
+	if checks != 0 {
+		t.Errorf("CachedClient.Lookup: expected partial entries to be used without a request, got %d requests", checks)
+	}
+
+	result, err = cachedClient.Lookup("8.8.8.8")
+
+	if err != nil || result.Partial || result.Response.Data.CountryName != "Australia" {
+		t.Errorf("CachedClient.Lookup: expected full verbose result for 8.8.8.8, got %+v (%v)", result, err)
+	}
+
+	if checks != 1 {
+		t.Errorf("CachedClient.Lookup: expected verbose lookup to make 1 request, got %d", checks)
+	}
+}
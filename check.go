@@ -33,22 +33,25 @@ type CheckResponse struct {
 // CheckBlockResponse represents the AbuseIPDB API response for a specific subnet/netblock that has been checked.
 type CheckBlockResponse struct {
 	Data struct {
-		NetworkAddress   string `json:"networkAddress"`
-		Netmask          string `json:"netmask"`
-		MinAddress       string `json:"minAddress"`
-		MaxAddress       string `json:"maxAddress"`
-		NumPossibleHosts int    `json:"numPossibleHosts"`
-		AddressSpaceDesc string `json:"addressSpaceDesc"`
-		ReportedAddress  []struct {
-			IPAddress            string    `json:"ipAddress"`
-			NumReports           int       `json:"numReports"`
-			MostRecentReport     time.Time `json:"mostRecentReport"`
-			AbuseConfidenceScore int       `json:"abuseConfidenceScore"`
-			CountryCode          string    `json:"countryCode"`
-		} `json:"reportedAddress"`
+		NetworkAddress   string            `json:"networkAddress"`
+		Netmask          string            `json:"netmask"`
+		MinAddress       string            `json:"minAddress"`
+		MaxAddress       string            `json:"maxAddress"`
+		NumPossibleHosts int               `json:"numPossibleHosts"`
+		AddressSpaceDesc string            `json:"addressSpaceDesc"`
+		ReportedAddress  []ReportedAddress `json:"reportedAddress"`
 	} `json:"data"`
 }
 
+// ReportedAddress represents an IP address within a checked subnet/netblock which has been reported.
+type ReportedAddress struct {
+	IPAddress            string    `json:"ipAddress"`
+	NumReports           int       `json:"numReports"`
+	MostRecentReport     time.Time `json:"mostRecentReport"`
+	AbuseConfidenceScore int       `json:"abuseConfidenceScore"`
+	CountryCode          string    `json:"countryCode"`
+}
+
 // Report represents the AbuseIPDB object for a report made about an IP address by a user.
 type Report struct {
 	ReportedAt          time.Time `json:"reportedAt"`
@@ -60,21 +63,32 @@ type Report struct {
 }
 
 type checkConfig struct {
-	verbose      bool
-	maxAgeInDays int
+	verbose        bool
+	maxAgeInDays   int
+	concurrency    int
+	maxBlocks      int
+	blockThreshold int
+	progress       func(completed, total int)
 }
 
 var defaultCheckConfig = checkConfig{
-	verbose:      true,
-	maxAgeInDays: 30,
+	verbose:        true,
+	maxAgeInDays:   30,
+	concurrency:    4,
+	maxBlocks:      256,
+	blockThreshold: 0,
 }
 
 var defaultCheckBlockConfig = checkConfig{
-	verbose:      true,
-	maxAgeInDays: 30,
+	verbose:        true,
+	maxAgeInDays:   30,
+	concurrency:    4,
+	maxBlocks:      256,
+	blockThreshold: 0,
 }
 
-// CheckOption sets an optional parameter for calls to the Check and CheckBlock endpoints.
+// CheckOption sets an optional parameter for calls to the Check and CheckBlock endpoints,
+// as well as the helpers built on top of them.
 type CheckOption func(*checkConfig)
 
 // Verbose returns a CheckOption that sets the verbose request parameter.
@@ -96,7 +110,34 @@ func MaxAgeInDays(days int) CheckOption {
 	}
 }
 
+// Concurrency returns a CheckOption that sets the maximum number of requests made at once by CheckNetwork and CheckMany.
+// The default value is 4.
+func Concurrency(requests int) CheckOption {
+	return func(config *checkConfig) {
+		config.concurrency = requests
+	}
+}
+
+// MaxBlocks returns a CheckOption that sets the maximum number of sub-blocks CheckNetwork may split a network into.
+// Each sub-block uses one request from the daily CheckBlock quota. The default value is 256.
+func MaxBlocks(blocks int) CheckOption {
+	return func(config *checkConfig) {
+		config.maxBlocks = blocks
+	}
+}
+
+// Progress returns a CheckOption that sets a callback which is called by CheckNetwork each time a request completes.
+// The callback is never called concurrently.
+func Progress(callback func(completed, total int)) CheckOption {
+	return func(config *checkConfig) {
+		config.progress = callback
+	}
+}
+
 // Check will return the stored information about the IP provided (either v4 or v6).
+// Unless Client.SkipAddressValidation is set, an InvalidAddressError is returned for addresses which aren't public.
+// Concurrent calls for the same IP and options share a single request, and receive the same response,
+// which must not be modified.
 func (c *Client) Check(ipAddress string, options ...CheckOption) (*CheckResponse, error) {
 	config := defaultCheckConfig
 
@@ -104,6 +145,12 @@ func (c *Client) Check(ipAddress string, options ...CheckOption) (*CheckResponse
 		option(&config)
 	}
 
+	ipAddress, err := c.normalizeIP(ipAddress)
+
+	if err != nil {
+		return nil, err
+	}
+
 	params := map[string]string{
 		"ipAddress": ipAddress,
 	}
@@ -118,29 +165,47 @@ func (c *Client) Check(ipAddress string, options ...CheckOption) (*CheckResponse
 
 	params["maxAgeInDays"] = strconv.Itoa(config.maxAgeInDays)
 
-	res, err := c.makeRequest("GET", "/check", RequestOptions{
-		Params: params,
-	})
+	// Concurrent checks of the same IP with the same options share a single request.
+	value, err := c.flights.do(checkKey(ipAddress, config), func() (interface{}, error) {
+		res, err := c.makeRequest("GET", "/check", RequestOptions{
+			Params: params,
+		})
 
-	if err != nil {
-		return nil, err
-	}
+		if err != nil {
+			return nil, err
+		}
 
-	responseBody, err := ioutil.ReadAll(res.Body)
+		responseBody, err := ioutil.ReadAll(res.Body)
 
-	if err != nil {
-		return nil, err
-	}
+		if err != nil {
+			return nil, err
+		}
+
+		checkResponse := CheckResponse{}
 
-	checkResponse := CheckResponse{}
+		err = json.Unmarshal(responseBody, &checkResponse)
 
-	err = json.Unmarshal(responseBody, &checkResponse)
+		if err != nil {
+			return nil, err
+		}
+
+		return &checkResponse, nil
+	})
 
 	if err != nil {
+		c.emit(Event{Type: EventCheck, IPAddress: ipAddress, Err: err})
+
 		return nil, err
 	}
 
-	return &checkResponse, nil
+	checkResponse := value.(*CheckResponse)
+	c.emit(Event{Type: EventCheck, IPAddress: ipAddress, Check: checkResponse})
+
+	return checkResponse, nil
+}
+
+func checkKey(ipAddress string, config checkConfig) string {
+	return fmt.Sprintf("check:%s:%t:%d", ipAddress, config.verbose, config.maxAgeInDays)
 }
 
 // CheckBlock will return the stored information about the subnet (either v4 or v6) provided, denoted with CIDR notation.
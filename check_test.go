@@ -63,6 +63,20 @@ func TestClient_Check(t *testing.T) {
 	}
 }
 
+func TestClient_CheckInvalidAddress(t *testing.T) {
+	client := NewClient("")
+
+	_, err := client.Check("127.0.0.1")
+
+	if addressError, ok := err.(InvalidAddressError); ok {
+		if addressError.Reason != ReasonLoopback {
+			t.Errorf(`Check: expected reason to be "%s", got "%s"`, ReasonLoopback, addressError.Reason)
+		}
+	} else {
+		t.Errorf("Check: expected err to be of type InvalidAddressError, got %v", err)
+	}
+}
+
 func TestClient_CheckBlock(t *testing.T) {
 	apiKey := os.Getenv("ABUSEIPDB_TOKEN")
 
@@ -0,0 +1,50 @@
+package abuseipdb
+
+import "sync"
+
+// flightGroup coalesces concurrent calls with the same key, so that only one of them does the work
+// and the others wait for, and share, its result.
+// The zero value is ready to use.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	wg    sync.WaitGroup
+	value interface{}
+	err   error
+}
+
+// do calls fn and returns its result, unless a call for the same key is already in flight,
+// in which case it waits for that call to complete and returns its result instead.
+func (g *flightGroup) do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		call.wg.Wait()
+
+		return call.value, call.err
+	}
+
+	call := &flightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		call.wg.Done()
+	}()
+
+	call.value, call.err = fn()
+
+	return call.value, call.err
+}
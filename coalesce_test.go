@@ -0,0 +1,110 @@
+package abuseipdb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlightGroup_do(t *testing.T) {
+	var group flightGroup
+
+	value, err := group.do("key", func() (interface{}, error) {
+		return "value", nil
+	})
+
+	if err != nil || value.(string) != "value" {
+		t.Errorf(`flightGroup.do: expected "value", got "%v" (%v)`, value, err)
+	}
+
+	_, err = group.do("key", func() (interface{}, error) {
+		return nil, errors.New("failed")
+	})
+
+	if err == nil || err.Error() != "failed" {
+		t.Errorf(`flightGroup.do: expected error to be "failed", got "%v"`, err)
+	}
+
+	if len(group.calls) != 0 {
+		t.Errorf("flightGroup.do: expected completed calls to be removed, got %d", len(group.calls))
+	}
+}
+
+func TestClient_CheckCoalescing(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(100 * time.Millisecond)
+
+		fmt.Fprintf(w, `{"data":{"ipAddress":"%s","abuseConfidenceScore":100}}`, r.URL.Query().Get("ipAddress"))
+	})
+
+	var wg sync.WaitGroup
+
+	responses := make([]*CheckResponse, 10)
+
+	for i := range responses {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			responses[i], _ = client.Check("1.1.1.1")
+		}(i)
+	}
+
+	wg.Wait()
+
+	if requests != 1 {
+		t.Errorf("Check: expected concurrent checks to share 1 request, got %d", requests)
+	}
+
+	for i, response := range responses {
+		if response != responses[0] {
+			t.Errorf("Check: expected response %d to be shared", i)
+		}
+	}
+}
+
+func TestClient_BlacklistCoalescing(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(100 * time.Millisecond)
+
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"errors":[{"detail":"Daily rate limit of 5 requests exceeded for this endpoint.","status":429}]}`)
+	})
+
+	var wg sync.WaitGroup
+
+	errs := make([]error, 5)
+
+	for i := range errs {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			_, errs[i] = client.Blacklist(Limit(100))
+		}(i)
+	}
+
+	wg.Wait()
+
+	if requests != 1 {
+		t.Errorf("Blacklist: expected concurrent calls to share 1 request, got %d", requests)
+	}
+
+	for i, err := range errs {
+		if requestError, ok := err.(RequestError); !ok || requestError.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("Blacklist: expected error %d to be a RequestError with status code 429, got %v", i, err)
+		}
+	}
+}
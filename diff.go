@@ -0,0 +1,246 @@
+package abuseipdb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+)
+
+// BlacklistDiff represents the differences between two blacklist snapshots.
+// Each list is sorted by IP address.
+type BlacklistDiff struct {
+	// From and To are the generatedAt times of the old and new snapshots.
+	From    time.Time
+	To      time.Time
+	Added   []BlacklistEntry
+	Removed []BlacklistEntry
+	Changed []BlacklistChange
+}
+
+// BlacklistChange represents an IP address in both blacklist snapshots whose entry has changed.
+type BlacklistChange struct {
+	Before BlacklistEntry
+	After  BlacklistEntry
+}
+
+// DeltaOp identifies the operation needed to apply a BlacklistDelta to a downstream copy of the blacklist.
+type DeltaOp int
+
+// A list of the operations a BlacklistDelta may represent.
+const (
+	// DeltaAdd means the IP address should be added.
+	DeltaAdd DeltaOp = iota + 1
+	// DeltaRemove means the IP address should be removed.
+	DeltaRemove
+	// DeltaUpdate means the score or last report time of the IP address should be updated.
+	DeltaUpdate
+)
+
+func (o DeltaOp) String() string {
+	switch o {
+	case DeltaAdd:
+		return "+"
+	case DeltaRemove:
+		return "-"
+	case DeltaUpdate:
+		return "~"
+	default:
+		return "?"
+	}
+}
+
+// BlacklistDelta represents a single change to apply to a downstream copy of the blacklist.
+// For DeltaRemove, Entry holds the entry being removed.
+type BlacklistDelta struct {
+	Op    DeltaOp
+	Entry BlacklistEntry
+}
+
+type diffConfig struct {
+	minScore       int
+	minScoreChange int
+	lastReported   bool
+}
+
+var defaultDiffConfig = diffConfig{
+	minScore:       0,
+	minScoreChange: 1,
+	lastReported:   true,
+}
+
+// DiffOption sets an optional parameter for calls to DiffBlacklists.
+type DiffOption func(*diffConfig)
+
+// DiffMinScore returns a DiffOption that ignores entries with an abuse confidence score below the value provided.
+// An IP whose score rises to meet the minimum is reported as added, and one whose score drops below it as removed.
+// The default value is 0, which includes every entry.
+func DiffMinScore(score int) DiffOption {
+	return func(config *diffConfig) {
+		config.minScore = score
+	}
+}
+
+// DiffMinScoreChange returns a DiffOption that sets the smallest change in abuse confidence score reported as a change.
+// The default value is 1, which reports every change.
+func DiffMinScoreChange(delta int) DiffOption {
+	return func(config *diffConfig) {
+		config.minScoreChange = delta
+	}
+}
+
+// DiffLastReported returns a DiffOption that sets whether a change in the time an IP was last reported is reported
+// as a change. This option is enabled by default.
+func DiffLastReported(enabled bool) DiffOption {
+	return func(config *diffConfig) {
+		config.lastReported = enabled
+	}
+}
+
+// DiffBlacklists compares two blacklist snapshots, returning the IP addresses which were added, removed and changed
+// between them. Either snapshot may be nil, in which case it is treated as empty.
+func DiffBlacklists(before *BlacklistResponse, after *BlacklistResponse, options ...DiffOption) *BlacklistDiff {
+	config := defaultDiffConfig
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	diff := BlacklistDiff{}
+	beforeEntries := diffEntries(before, config, &diff.From)
+	afterEntries := diffEntries(after, config, &diff.To)
+
+	for ipAddress, entry := range afterEntries {
+		previous, ok := beforeEntries[ipAddress]
+
+		if !ok {
+			diff.Added = append(diff.Added, entry)
+
+			continue
+		}
+
+		scoreChange := entry.AbuseConfidenceScore - previous.AbuseConfidenceScore
+
+		if scoreChange < 0 {
+			scoreChange = -scoreChange
+		}
+
+		if (scoreChange > 0 && scoreChange >= config.minScoreChange) ||
+			(config.lastReported && !entry.LastReportedAt.Equal(previous.LastReportedAt)) {
+			diff.Changed = append(diff.Changed, BlacklistChange{
+				Before: previous,
+				After:  entry,
+			})
+		}
+	}
+
+	for ipAddress, entry := range beforeEntries {
+		if _, ok := afterEntries[ipAddress]; !ok {
+			diff.Removed = append(diff.Removed, entry)
+		}
+	}
+
+	sortEntries(diff.Added)
+	sortEntries(diff.Removed)
+	sortChanges(diff.Changed)
+
+	return &diff
+}
+
+func diffEntries(response *BlacklistResponse, config diffConfig, generatedAt *time.Time) map[string]BlacklistEntry {
+	entries := make(map[string]BlacklistEntry)
+
+	if response == nil {
+		return entries
+	}
+
+	*generatedAt = response.Meta.GeneratedAt
+
+	for _, entry := range response.Data {
+		if entry.AbuseConfidenceScore < config.minScore {
+			continue
+		}
+
+		entry.IPAddress = canonicalIP(entry.IPAddress)
+		entries[entry.IPAddress] = entry
+	}
+
+	return entries
+}
+
+func sortChanges(changes []BlacklistChange) {
+	entries := make([]BlacklistEntry, len(changes))
+	byIP := make(map[string]BlacklistChange, len(changes))
+
+	for i, change := range changes {
+		entries[i] = change.After
+		byIP[change.After.IPAddress] = change
+	}
+
+	sortEntries(entries)
+
+	for i, entry := range entries {
+		changes[i] = byIP[entry.IPAddress]
+	}
+}
+
+// Empty reports whether the snapshots were the same.
+func (d *BlacklistDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Deltas returns the changes needed to bring a downstream copy of the old snapshot up to date with the new one.
+// Removals are listed first, followed by additions and then updates.
+func (d *BlacklistDiff) Deltas() []BlacklistDelta {
+	deltas := make([]BlacklistDelta, 0, len(d.Removed)+len(d.Added)+len(d.Changed))
+
+	for _, entry := range d.Removed {
+		deltas = append(deltas, BlacklistDelta{Op: DeltaRemove, Entry: entry})
+	}
+
+	for _, entry := range d.Added {
+		deltas = append(deltas, BlacklistDelta{Op: DeltaAdd, Entry: entry})
+	}
+
+	for _, change := range d.Changed {
+		deltas = append(deltas, BlacklistDelta{Op: DeltaUpdate, Entry: change.After})
+	}
+
+	return deltas
+}
+
+// WriteTo writes the deltas to w as text, one per line, preceded by a comment line holding the generatedAt times
+// of both snapshots. Each line holds the operation (+, - or ~), the IP address and, except for removals,
+// the abuse confidence score and the time of the last report in RFC 3339 format:
+//
+//	# 2021-08-18T10:00:00Z 2021-08-18T16:00:00Z
+//	- 192.0.2.1
+//	+ 198.51.100.7 100 2021-08-18T15:32:10Z
+//	~ 203.0.113.5 75 2021-08-18T14:01:00Z
+func (d *BlacklistDiff) WriteTo(w io.Writer) (int64, error) {
+	writer := bufio.NewWriter(w)
+	var written int64
+
+	n, err := fmt.Fprintf(writer, "# %s %s\n", d.From.UTC().Format(time.RFC3339), d.To.UTC().Format(time.RFC3339))
+	written += int64(n)
+
+	for _, delta := range d.Deltas() {
+		if err != nil {
+			break
+		}
+
+		if delta.Op == DeltaRemove {
+			n, err = fmt.Fprintf(writer, "%s %s\n", delta.Op, delta.Entry.IPAddress)
+		} else {
+			n, err = fmt.Fprintf(writer, "%s %s %d %s\n", delta.Op, delta.Entry.IPAddress, delta.Entry.AbuseConfidenceScore, delta.Entry.LastReportedAt.UTC().Format(time.RFC3339))
+		}
+
+		written += int64(n)
+	}
+
+	if err != nil {
+		return written, err
+	}
+
+	return written, writer.Flush()
+}
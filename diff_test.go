@@ -0,0 +1,113 @@
+package abuseipdb
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func testBlacklist(generatedAt time.Time, entries ...BlacklistEntry) *BlacklistResponse {
+	response := BlacklistResponse{}
+	response.Meta.GeneratedAt = generatedAt
+	response.Data = entries
+
+	return &response
+}
+
+func TestDiffBlacklists(t *testing.T) {
+	reported := time.Date(2021, 8, 18, 9, 0, 0, 0, time.UTC)
+	before := testBlacklist(time.Date(2021, 8, 18, 10, 0, 0, 0, time.UTC),
+		BlacklistEntry{IPAddress: "8.8.8.8", AbuseConfidenceScore: 50, LastReportedAt: reported},
+		BlacklistEntry{IPAddress: "1.1.1.1", AbuseConfidenceScore: 100, LastReportedAt: reported},
+		BlacklistEntry{IPAddress: "9.9.9.9", AbuseConfidenceScore: 80, LastReportedAt: reported},
+		BlacklistEntry{IPAddress: "2606:4700:0::1111", AbuseConfidenceScore: 75, LastReportedAt: reported},
+	)
+	after := testBlacklist(time.Date(2021, 8, 18, 16, 0, 0, 0, time.UTC),
+		BlacklistEntry{IPAddress: "1.1.1.1", AbuseConfidenceScore: 100, LastReportedAt: reported.Add(time.Hour)},
+		BlacklistEntry{IPAddress: "9.9.9.9", AbuseConfidenceScore: 82, LastReportedAt: reported},
+		BlacklistEntry{IPAddress: "2606:4700::1111", AbuseConfidenceScore: 75, LastReportedAt: reported},
+		BlacklistEntry{IPAddress: "4.4.4.4", AbuseConfidenceScore: 60, LastReportedAt: reported},
+	)
+
+	diff := DiffBlacklists(before, after)
+
+	if !diff.From.Equal(before.Meta.GeneratedAt) || !diff.To.Equal(after.Meta.GeneratedAt) {
+		t.Errorf("DiffBlacklists: expected From and To to be the generatedAt times, got %v and %v", diff.From, diff.To)
+	}
+
+	if len(diff.Added) != 1 || diff.Added[0].IPAddress != "4.4.4.4" {
+		t.Errorf("DiffBlacklists: expected 4.4.4.4 to be added, got %+v", diff.Added)
+	}
+
+	if len(diff.Removed) != 1 || diff.Removed[0].IPAddress != "8.8.8.8" {
+		t.Errorf("DiffBlacklists: expected 8.8.8.8 to be removed, got %+v", diff.Removed)
+	}
+
+	if len(diff.Changed) != 2 || diff.Changed[0].After.IPAddress != "1.1.1.1" || diff.Changed[1].After.IPAddress != "9.9.9.9" {
+		t.Errorf("DiffBlacklists: expected 1.1.1.1 and 9.9.9.9 to be changed, got %+v", diff.Changed)
+	}
+
+	diff = DiffBlacklists(before, after, DiffMinScoreChange(5), DiffLastReported(false))
+
+	if len(diff.Changed) != 0 {
+		t.Errorf("DiffBlacklists: expected no changes with DiffMinScoreChange(5) and DiffLastReported(false), got %+v", diff.Changed)
+	}
+
+	diff = DiffBlacklists(before, after, DiffMinScore(75))
+
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Errorf("DiffBlacklists: expected entries below DiffMinScore(75) to be ignored, got %+v added and %+v removed", diff.Added, diff.Removed)
+	}
+
+	diff = DiffBlacklists(before, before)
+
+	if !diff.Empty() {
+		t.Errorf("BlacklistDiff.Empty: expected a diff of the same snapshot to be empty, got %+v", diff)
+	}
+
+	diff = DiffBlacklists(nil, after)
+
+	if len(diff.Added) != 4 || diff.Added[3].IPAddress != "2606:4700::1111" {
+		t.Errorf("DiffBlacklists: expected every entry to be added when diffing from nil, with IPv6 last, got %+v", diff.Added)
+	}
+}
+
+func TestBlacklistDiff_WriteTo(t *testing.T) {
+	reported := time.Date(2021, 8, 18, 9, 0, 0, 0, time.UTC)
+	before := testBlacklist(time.Date(2021, 8, 18, 10, 0, 0, 0, time.UTC),
+		BlacklistEntry{IPAddress: "192.0.2.1", AbuseConfidenceScore: 100, LastReportedAt: reported},
+		BlacklistEntry{IPAddress: "203.0.113.5", AbuseConfidenceScore: 50, LastReportedAt: reported},
+	)
+	after := testBlacklist(time.Date(2021, 8, 18, 16, 0, 0, 0, time.UTC),
+		BlacklistEntry{IPAddress: "203.0.113.5", AbuseConfidenceScore: 75, LastReportedAt: reported},
+		BlacklistEntry{IPAddress: "198.51.100.7", AbuseConfidenceScore: 100, LastReportedAt: reported},
+	)
+
+	diff := DiffBlacklists(before, after)
+	deltas := diff.Deltas()
+
+	if len(deltas) != 3 || deltas[0].Op != DeltaRemove || deltas[1].Op != DeltaAdd || deltas[2].Op != DeltaUpdate {
+		t.Errorf("BlacklistDiff.Deltas: expected a removal, an addition and an update, got %+v", deltas)
+	}
+
+	buffer := bytes.Buffer{}
+	n, err := diff.WriteTo(&buffer)
+
+	if err != nil {
+		t.Logf("BlacklistDiff.WriteTo: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	expected := "# 2021-08-18T10:00:00Z 2021-08-18T16:00:00Z\n" +
+		"- 192.0.2.1\n" +
+		"+ 198.51.100.7 100 2021-08-18T09:00:00Z\n" +
+		"~ 203.0.113.5 75 2021-08-18T09:00:00Z\n"
+
+	if buffer.String() != expected {
+		t.Errorf("BlacklistDiff.WriteTo: expected %q, got %q", expected, buffer.String())
+	}
+
+	if n != int64(len(expected)) {
+		t.Errorf("BlacklistDiff.WriteTo: expected %d bytes written, got %d", len(expected), n)
+	}
+}
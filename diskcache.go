@@ -0,0 +1,367 @@
+package abuseipdb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// diskCacheCompactRecords is the minimum number of records in the log before it is compacted.
+const diskCacheCompactRecords = 1024
+
+// DiskCache is a Cache which persists entries to a file, so that they survive restarts.
+// Entries are appended to a log, which is compacted once it holds more than twice as many records as live entries.
+// Several processes on the same host may share a cache file, access to which is coordinated with file locks.
+// Use OpenDiskCache to initialise a new cache.
+type DiskCache struct {
+	mu      sync.Mutex
+	path    string
+	lock    *os.File
+	file    *os.File
+	offset  int64
+	partial bool
+	records int
+	entries map[string]CacheEntry
+	err     error
+	now     func() time.Time
+}
+
+type diskCacheRecord struct {
+	Key string `json:"key"`
+	// Entry is nil for records which delete a key.
+	Entry *CacheEntry `json:"entry,omitempty"`
+}
+
+// OpenDiskCache opens the cache file at path, creating it if it doesn't exist.
+// A lock file is created alongside it, with the ".lock" suffix.
+// Entries which have already expired are discarded as the file is loaded.
+func OpenDiskCache(path string) (*DiskCache, error) {
+	lock, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0644)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cache := DiskCache{
+		path: path,
+		lock: lock,
+		now:  time.Now,
+	}
+
+	err = cache.withLock(true, cache.refresh)
+
+	if err != nil {
+		lock.Close()
+
+		return nil, err
+	}
+
+	return &cache, nil
+}
+
+// Get returns the entry stored for key, if present and not expired.
+// Entries written by other processes since the last call are loaded first.
+func (d *DiskCache) Get(key string) (CacheEntry, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.setErr(d.withLock(false, d.refresh))
+
+	entry, ok := d.entries[key]
+
+	if !ok || entry.Expired(d.now()) {
+		return CacheEntry{}, false
+	}
+
+	return entry, true
+}
+
+// Set stores an entry for key, appending it to the cache file.
+func (d *DiskCache) Set(key string, entry CacheEntry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.setErr(d.withLock(true, func() error {
+		return d.append(diskCacheRecord{Key: key, Entry: &entry})
+	}))
+}
+
+// Delete removes the entry stored for key.
+func (d *DiskCache) Delete(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.setErr(d.withLock(true, func() error {
+		return d.append(diskCacheRecord{Key: key})
+	}))
+}
+
+// Err returns the last error which occurred whilst reading or writing the cache file.
+// The Cache interface doesn't return errors, so they are recorded here instead.
+func (d *DiskCache) Err() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.err
+}
+
+// Compact rewrites the cache file so that it only contains live entries.
+func (d *DiskCache) Compact() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.withLock(true, func() error {
+		err := d.refresh()
+
+		if err != nil {
+			return err
+		}
+
+		return d.compact()
+	})
+}
+
+// Close closes the cache file.
+func (d *DiskCache) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.file != nil {
+		d.file.Close()
+	}
+
+	return d.lock.Close()
+}
+
+func (d *DiskCache) setErr(err error) {
+	if err != nil {
+		d.err = err
+	}
+}
+
+func (d *DiskCache) withLock(exclusive bool, fn func() error) error {
+	err := lockFile(d.lock, exclusive)
+
+	if err != nil {
+		return err
+	}
+
+	defer unlockFile(d.lock)
+
+	return fn()
+}
+
+// refresh loads any records appended to the cache file since it was last read.
+// If the file has been replaced by another process compacting it, it is reloaded from the start.
+func (d *DiskCache) refresh() error {
+	info, err := os.Stat(d.path)
+
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	reopen := d.file == nil || info == nil || info.Size() < d.offset
+
+	if !reopen {
+		current, err := d.file.Stat()
+
+		if err != nil {
+			return err
+		}
+
+		reopen = !os.SameFile(info, current)
+	}
+
+	if reopen {
+		if d.file != nil {
+			d.file.Close()
+		}
+
+		d.file, err = os.OpenFile(d.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+
+		if err != nil {
+			d.file = nil
+
+			return err
+		}
+
+		d.offset = 0
+		d.partial = false
+		d.records = 0
+		d.entries = make(map[string]CacheEntry)
+
+		info, err = d.file.Stat()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if info.Size() == d.offset {
+		return nil
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(d.file, d.offset, info.Size()-d.offset))
+	now := d.now()
+
+	for {
+		line, err := reader.ReadBytes('\n')
+
+		if err == io.EOF {
+			// A trailing line without a newline was left by an interrupted write, and is skipped.
+			// The next record appended terminates it, so that it doesn't corrupt the record which follows.
+			d.offset += int64(len(line))
+			d.partial = len(line) > 0
+
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		d.offset += int64(len(line))
+		d.records++
+
+		record := diskCacheRecord{}
+
+		if json.Unmarshal(bytes.TrimSpace(line), &record) != nil {
+			continue
+		}
+
+		if record.Entry == nil || record.Entry.Expired(now) {
+			delete(d.entries, record.Key)
+		} else {
+			d.entries[record.Key] = *record.Entry
+		}
+	}
+}
+
+func (d *DiskCache) append(record diskCacheRecord) error {
+	err := d.refresh()
+
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(record)
+
+	if err != nil {
+		return err
+	}
+
+	if d.partial {
+		line = append([]byte("\n"), line...)
+	}
+
+	line = append(line, '\n')
+
+	n, err := d.file.Write(line)
+	d.offset += int64(n)
+
+	if err != nil {
+		return err
+	}
+
+	d.partial = false
+	d.records++
+
+	if record.Entry == nil {
+		delete(d.entries, record.Key)
+	} else {
+		d.entries[record.Key] = *record.Entry
+	}
+
+	if d.records >= diskCacheCompactRecords && d.records > 2*len(d.entries) {
+		return d.compact()
+	}
+
+	return nil
+}
+
+// compact writes the live entries to a temporary file, which then atomically replaces the cache file.
+// Other processes notice the replacement the next time they refresh, and reload the file.
+func (d *DiskCache) compact() error {
+	temp, err := ioutil.TempFile(filepath.Dir(d.path), filepath.Base(d.path)+".tmp*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(temp.Name())
+
+	writer := bufio.NewWriter(temp)
+	encoder := json.NewEncoder(writer)
+	now := d.now()
+
+	for key, entry := range d.entries {
+		if entry.Expired(now) {
+			delete(d.entries, key)
+
+			continue
+		}
+
+		entry := entry
+
+		err = encoder.Encode(diskCacheRecord{Key: key, Entry: &entry})
+
+		if err != nil {
+			temp.Close()
+
+			return err
+		}
+	}
+
+	err = writer.Flush()
+
+	if err == nil {
+		err = temp.Sync()
+	}
+
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = chmodLike(temp.Name(), d.path)
+
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(temp.Name(), d.path)
+
+	if err != nil {
+		return err
+	}
+
+	// Reload from the compacted file, so that the offset and record count match it.
+	d.file.Close()
+	d.file = nil
+
+	return d.refresh()
+}
+
+// chmodLike gives the file at temp the permissions of the file at path, or 0644 if there isn't one, so replacing the
+// file with it doesn't change who can read it. Temporary files are otherwise only readable by their owner.
+func chmodLike(temp string, path string) error {
+	mode := os.FileMode(0644)
+	info, err := os.Stat(path)
+
+	if err == nil {
+		mode = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	return os.Chmod(temp, mode)
+}
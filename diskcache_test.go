@@ -0,0 +1,146 @@
+package abuseipdb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiskCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.log")
+
+	first, err := OpenDiskCache(path)
+
+	if err != nil {
+		t.Logf("OpenDiskCache: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	defer first.Close()
+
+	second, err := OpenDiskCache(path)
+
+	if err != nil {
+		t.Logf("OpenDiskCache: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	defer second.Close()
+
+	now := time.Now()
+	checkResponse := &CheckResponse{}
+	checkResponse.Data.IPAddress = "1.1.1.1"
+	checkBlockResponse := &CheckBlockResponse{}
+	checkBlockResponse.Data.NumPossibleHosts = 254
+
+	first.Set("check", CacheEntry{Check: checkResponse, FetchedAt: now, ExpiresAt: now.Add(time.Hour)})
+	first.Set("block", CacheEntry{CheckBlock: checkBlockResponse, FetchedAt: now, ExpiresAt: now.Add(time.Hour)})
+	first.Set("expired", CacheEntry{Check: checkResponse, FetchedAt: now, ExpiresAt: now.Add(-time.Second)})
+
+	// Entries written by one instance are visible to another sharing the file.
+	entry, ok := second.Get("check")
+
+	if !ok || entry.Check.Data.IPAddress != "1.1.1.1" {
+		t.Errorf(`DiskCache.Get: expected entry for "check" with IP address "1.1.1.1", got %v`, entry)
+	}
+
+	if !entry.FetchedAt.Equal(now) {
+		t.Errorf("DiskCache.Get: expected fetch time %v, got %v", now, entry.FetchedAt)
+	}
+
+	entry, ok = second.Get("block")
+
+	if !ok || entry.CheckBlock.Data.NumPossibleHosts != 254 {
+		t.Errorf(`DiskCache.Get: expected entry for "block" with 254 possible hosts, got %v`, entry)
+	}
+
+	if _, ok = second.Get("expired"); ok {
+		t.Errorf(`DiskCache.Get: expected "expired" not to be returned`)
+	}
+
+	second.Delete("block")
+
+	if _, ok = first.Get("block"); ok {
+		t.Errorf(`DiskCache.Get: expected "block" to have been deleted`)
+	}
+
+	// Compaction keeps the permissions of the file, so it stays readable by other users sharing it.
+	_ = os.Chmod(path, 0640)
+
+	err = first.Compact()
+
+	if err != nil {
+		t.Logf("DiskCache.Compact: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm() != 0640 {
+		t.Errorf("DiskCache.Compact: expected the file mode to be kept as 0640, got %v", info.Mode().Perm())
+	}
+
+	contents, _ := ioutil.ReadFile(path)
+
+	if lines := strings.Count(string(contents), "\n"); lines != 1 {
+		t.Errorf("DiskCache.Compact: expected 1 record after compaction, got %d", lines)
+	}
+
+	// The other instance reloads the replaced file, and can keep appending to it.
+	second.Set("other", CacheEntry{Check: checkResponse, FetchedAt: now})
+
+	if _, ok = first.Get("other"); !ok {
+		t.Errorf(`DiskCache.Get: expected entry for "other" after compaction`)
+	}
+
+	if _, ok = first.Get("check"); !ok {
+		t.Errorf(`DiskCache.Get: expected entry for "check" after compaction`)
+	}
+
+	if first.Err() != nil || second.Err() != nil {
+		t.Errorf("DiskCache.Err: expected nil, got %v and %v", first.Err(), second.Err())
+	}
+}
+
+func TestDiskCache_PartialRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.log")
+
+	err := ioutil.WriteFile(path, []byte(`{"key":"a","entry":{"fetchedAt":"2021-08-18T10:00:37Z","expiresAt":"0001-01-01T00:00:00Z"}}`+"\n"+`{"key":"b","ent`), 0644)
+
+	if err != nil {
+		t.Logf("DiskCache: an error occurred whilst writing the test file: %v", err)
+		t.FailNow()
+	}
+
+	cache, err := OpenDiskCache(path)
+
+	if err != nil {
+		t.Logf("OpenDiskCache: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if _, ok := cache.Get("a"); !ok {
+		t.Errorf(`DiskCache.Get: expected entry for "a"`)
+	}
+
+	cache.Set("c", CacheEntry{FetchedAt: time.Now()})
+	cache.Close()
+
+	cache, err = OpenDiskCache(path)
+
+	if err != nil {
+		t.Logf("OpenDiskCache: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	defer cache.Close()
+
+	if _, ok := cache.Get("c"); !ok {
+		t.Errorf(`DiskCache.Get: expected entry for "c" written after a partial record`)
+	}
+
+	if _, err := os.Stat(path + ".lock"); err != nil {
+		t.Errorf("OpenDiskCache: expected lock file to exist, got %v", err)
+	}
+}
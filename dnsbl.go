@@ -0,0 +1,527 @@
+package abuseipdb
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DNS message constants used by DNSBLServer, as defined in RFC 1035.
+const (
+	dnsHeaderLen     = 12
+	dnsMaxUDPLen     = 512
+	dnsMaxNameLen    = 255
+	dnsTypeA         = 1
+	dnsTypeSOA       = 6
+	dnsTypeTXT       = 16
+	dnsTypeANY       = 255
+	dnsClassIN       = 1
+	dnsClassANY      = 255
+	dnsRcodeFormErr  = 1
+	dnsRcodeServFail = 2
+	dnsRcodeNXDomain = 3
+	dnsRcodeNotImp   = 4
+	dnsRcodeRefused  = 5
+)
+
+// dnsblTCPIdleTimeout is how long a TCP connection to a DNSBLServer is kept open while waiting for a query.
+const dnsblTCPIdleTimeout = 10 * time.Second
+
+// DNSBLServer is a DNS blocklist (DNSBL) server, answering queries for the addresses in a blacklist as described
+// in RFC 5782. IPv4 addresses are queried with their octets reversed, such as 4.3.2.1.<zone> for 1.2.3.4,
+// and IPv6 addresses with their nibbles reversed. Listed addresses have an A record encoding their abuse
+// confidence score: 127.0.0.2 for scores below 25, 127.0.0.3 from 25, 127.0.0.4 from 50 and 127.0.0.5 from 75.
+// They also have a TXT record with their score and a link to their AbuseIPDB page. As RFC 5782 requires,
+// 127.0.0.2 and ::ffff:7f00:2 are always listed, so the server can be tested.
+// Use NewDNSBLServer to initialise a new server.
+type DNSBLServer struct {
+	zone     []string
+	source   func() *BlacklistSet
+	ttl      uint32
+	minScore int
+}
+
+type dnsblConfig struct {
+	ttl      time.Duration
+	minScore int
+}
+
+// DNSBLOption sets an optional parameter when creating a DNSBLServer.
+type DNSBLOption func(*dnsblConfig)
+
+// DNSBLTTL returns a DNSBLOption that sets how long resolvers may cache answers, rounded down to the second.
+// The default TTL is 15 minutes.
+func DNSBLTTL(ttl time.Duration) DNSBLOption {
+	return func(config *dnsblConfig) {
+		config.ttl = ttl
+	}
+}
+
+// DNSBLMinScore returns a DNSBLOption that leaves out addresses with an abuse confidence score below the value
+// provided, so they aren't listed. The default value is 0, which lists every address in the blacklist.
+func DNSBLMinScore(score int) DNSBLOption {
+	return func(config *dnsblConfig) {
+		config.minScore = score
+	}
+}
+
+// NewDNSBLServer initialises a new server answering queries under the zone provided, such as "bl.example.com".
+// The source is called for each query to get the blacklist to answer from, so a BlacklistRefresher can be used
+// by passing its Current method. Queries are answered with SERVFAIL while the source returns nil.
+func NewDNSBLServer(zone string, source func() *BlacklistSet, options ...DNSBLOption) *DNSBLServer {
+	config := dnsblConfig{
+		ttl:      15 * time.Minute,
+		minScore: 0,
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	var labels []string
+
+	for _, label := range strings.Split(strings.ToLower(strings.Trim(zone, ".")), ".") {
+		if label != "" {
+			labels = append(labels, label)
+		}
+	}
+
+	if config.ttl < 0 {
+		config.ttl = 0
+	}
+
+	server := DNSBLServer{
+		zone:     labels,
+		source:   source,
+		ttl:      uint32(config.ttl / time.Second),
+		minScore: config.minScore,
+	}
+
+	return &server
+}
+
+// ListenAndServe listens on the address provided over both UDP and TCP, and answers queries until the context is
+// cancelled, returning the context's error. If either listener fails, both are closed and the error is returned.
+func (s *DNSBLServer) ListenAndServe(ctx context.Context, address string) error {
+	packetConn, err := net.ListenPacket("udp", address)
+
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", address)
+
+	if err != nil {
+		packetConn.Close()
+
+		return err
+	}
+
+	errs := make(chan error, 2)
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		errs <- s.ServePacket(packetConn)
+	}()
+
+	go func() {
+		defer wg.Done()
+		errs <- s.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+	case err = <-errs:
+	}
+
+	packetConn.Close()
+	listener.Close()
+	wg.Wait()
+
+	return err
+}
+
+// ServePacket answers queries received on a packet connection, such as a UDP socket, until reading from it fails.
+// Answers which don't fit in a 512 byte message are truncated, so the resolver retries over TCP.
+func (s *DNSBLServer) ServePacket(conn net.PacketConn) error {
+	buffer := make([]byte, 65535)
+
+	for {
+		n, addr, err := conn.ReadFrom(buffer)
+
+		if err != nil {
+			if netError, ok := err.(net.Error); ok && netError.Temporary() {
+				continue
+			}
+
+			return err
+		}
+
+		response := s.answer(buffer[:n])
+
+		if response == nil {
+			continue
+		}
+
+		if len(response) > dnsMaxUDPLen {
+			response = dnsTruncate(response)
+		}
+
+		_, _ = conn.WriteTo(response, addr)
+	}
+}
+
+// Serve accepts TCP connections on the listener and answers the queries received on them, until accepting a
+// connection fails. Each connection is closed once it has been idle for ten seconds.
+func (s *DNSBLServer) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+
+		if err != nil {
+			if netError, ok := err.(net.Error); ok && netError.Temporary() {
+				continue
+			}
+
+			return err
+		}
+
+		go s.serveConn(conn)
+	}
+}
+
+// serveConn answers the queries received on a TCP connection, each of which is prefixed with its length.
+func (s *DNSBLServer) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	length := make([]byte, 2)
+
+	for {
+		_ = conn.SetDeadline(time.Now().Add(dnsblTCPIdleTimeout))
+
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return
+		}
+
+		query := make([]byte, binary.BigEndian.Uint16(length))
+
+		if _, err := io.ReadFull(conn, query); err != nil {
+			return
+		}
+
+		response := s.answer(query)
+
+		if response == nil {
+			return
+		}
+
+		if _, err := conn.Write(append(appendUint16(nil, uint16(len(response))), response...)); err != nil {
+			return
+		}
+	}
+}
+
+// dnsQuestion is the question of a query, with the offsets of its labels so answers can point to them.
+type dnsQuestion struct {
+	raw     []byte
+	labels  []string
+	offsets []int
+	qtype   uint16
+	qclass  uint16
+}
+
+// answer returns the response to a query, or nil if the message isn't a query and should be ignored.
+func (s *DNSBLServer) answer(query []byte) []byte {
+	if len(query) < dnsHeaderLen || query[2]&0x80 != 0 {
+		return nil
+	}
+
+	response := dnsResponse{id: binary.BigEndian.Uint16(query), flags: uint16(query[2]&0x79) << 8}
+
+	if opcode := (query[2] >> 3) & 0xf; opcode != 0 {
+		return response.pack(dnsRcodeNotImp)
+	}
+
+	question, ok := parseDNSQuestion(query)
+
+	if !ok {
+		return response.pack(dnsRcodeFormErr)
+	}
+
+	response.question = question.raw
+
+	if question.qclass != dnsClassIN && question.qclass != dnsClassANY {
+		return response.pack(dnsRcodeRefused)
+	}
+
+	prefix := len(question.labels) - len(s.zone)
+
+	if prefix < 0 || !equalFoldLabels(question.labels[prefix:], s.zone) {
+		return response.pack(dnsRcodeRefused)
+	}
+
+	set := s.source()
+
+	if set == nil {
+		return response.pack(dnsRcodeServFail)
+	}
+
+	response.authoritative = true
+	zone := question.offsets[prefix]
+	soa := s.soa(zone, set.GeneratedAt())
+
+	if prefix == 0 {
+		if question.qtype == dnsTypeSOA || question.qtype == dnsTypeANY {
+			response.answers = append(response.answers, soa)
+		} else {
+			response.authority = append(response.authority, soa)
+		}
+
+		return response.pack(0)
+	}
+
+	ip := dnsblAddress(question.labels[:prefix])
+
+	if ip == nil {
+		response.authority = append(response.authority, soa)
+
+		return response.pack(dnsRcodeNXDomain)
+	}
+
+	score, text := 100, "AbuseIPDB DNSBL test entry"
+
+	if !ip.Equal(net.IPv4(127, 0, 0, 2)) {
+		match, ok := set.Lookup(ip)
+
+		if !ok || match.AbuseConfidenceScore < s.minScore {
+			response.authority = append(response.authority, soa)
+
+			return response.pack(dnsRcodeNXDomain)
+		}
+
+		score = match.AbuseConfidenceScore
+		text = fmt.Sprintf("Listed by AbuseIPDB with an abuse confidence score of %d: https://www.abuseipdb.com/check/%s",
+			score, ip.String())
+	}
+
+	if question.qtype == dnsTypeA || question.qtype == dnsTypeANY {
+		response.answers = append(response.answers, dnsRecord(dnsHeaderLen, dnsTypeA, s.ttl, dnsblBand(score)))
+	}
+
+	if question.qtype == dnsTypeTXT || question.qtype == dnsTypeANY {
+		response.answers = append(response.answers, dnsRecord(dnsHeaderLen, dnsTypeTXT, s.ttl, append([]byte{byte(len(text))}, text...)))
+	}
+
+	if len(response.answers) == 0 {
+		response.authority = append(response.authority, soa)
+	}
+
+	return response.pack(0)
+}
+
+// soa returns the SOA record of the zone, whose name is at the offset provided. The serial is the time the
+// blacklist was generated, so it changes with each refresh, or the current time if that isn't known.
+func (s *DNSBLServer) soa(zone int, generatedAt time.Time) []byte {
+	pointer := appendUint16(nil, 0xc000|uint16(zone))
+	rdata := append([]byte{}, pointer...)
+	rdata = append(rdata, 10)
+	rdata = append(rdata, "hostmaster"...)
+	rdata = append(rdata, pointer...)
+
+	for _, value := range []uint32{zoneSerial(generatedAt), 3600, 600, 86400, s.ttl} {
+		rdata = appendUint32(rdata, value)
+	}
+
+	return dnsRecord(zone, dnsTypeSOA, s.ttl, rdata)
+}
+
+// parseDNSQuestion parses the single question of a query.
+func parseDNSQuestion(query []byte) (dnsQuestion, bool) {
+	if binary.BigEndian.Uint16(query[4:]) != 1 {
+		return dnsQuestion{}, false
+	}
+
+	question := dnsQuestion{}
+	offset := dnsHeaderLen
+
+	for {
+		if offset >= len(query) || offset-dnsHeaderLen >= dnsMaxNameLen {
+			return dnsQuestion{}, false
+		}
+
+		length := int(query[offset])
+		question.offsets = append(question.offsets, offset)
+
+		if length == 0 {
+			break
+		}
+
+		// Questions are never compressed, so a pointer or an extended label type makes the query malformed.
+		if length&0xc0 != 0 || offset+1+length > len(query) {
+			return dnsQuestion{}, false
+		}
+
+		question.labels = append(question.labels, string(query[offset+1:offset+1+length]))
+		offset += 1 + length
+	}
+
+	end := offset + 5
+
+	if end > len(query) {
+		return dnsQuestion{}, false
+	}
+
+	question.raw = query[dnsHeaderLen:end]
+	question.qtype = binary.BigEndian.Uint16(query[offset+1:])
+	question.qclass = binary.BigEndian.Uint16(query[offset+3:])
+
+	return question, true
+}
+
+// dnsblAddress returns the address queried by the labels before the zone, which are either the four octets of
+// an IPv4 address or the 32 nibbles of an IPv6 address, in reverse order. It returns nil for any other name.
+func dnsblAddress(labels []string) net.IP {
+	switch len(labels) {
+	case net.IPv4len:
+		ip := make(net.IP, net.IPv4len)
+
+		for i, label := range labels {
+			octet, err := strconv.ParseUint(label, 10, 8)
+
+			if err != nil || strconv.FormatUint(octet, 10) != label {
+				return nil
+			}
+
+			ip[net.IPv4len-1-i] = byte(octet)
+		}
+
+		return ip
+	case net.IPv6len * 2:
+		ip := make(net.IP, net.IPv6len)
+
+		for i, label := range labels {
+			nibble, err := strconv.ParseUint(label, 16, 4)
+
+			if err != nil || len(label) != 1 {
+				return nil
+			}
+
+			index := net.IPv6len*2 - 1 - i
+			ip[index/2] |= byte(nibble) << (4 * uint(1-index%2))
+		}
+
+		return ip
+	default:
+		return nil
+	}
+}
+
+// dnsblBand returns the A record data encoding an abuse confidence score.
+func dnsblBand(score int) []byte {
+	band := 2
+
+	switch {
+	case score >= 75:
+		band = 5
+	case score >= 50:
+		band = 4
+	case score >= 25:
+		band = 3
+	}
+
+	return []byte{127, 0, 0, byte(band)}
+}
+
+func equalFoldLabels(labels []string, zone []string) bool {
+	for i := range labels {
+		if !strings.EqualFold(labels[i], zone[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dnsResponse is a response being built, holding the question and records to pack.
+type dnsResponse struct {
+	id            uint16
+	flags         uint16
+	authoritative bool
+	question      []byte
+	answers       [][]byte
+	authority     [][]byte
+}
+
+// pack returns the response as a DNS message, echoing the question and copying the opcode and RD flag from the query.
+func (r dnsResponse) pack(rcode int) []byte {
+	flags := 0x8000 | r.flags | uint16(rcode)
+
+	if r.authoritative {
+		flags |= 0x0400
+	}
+
+	message := make([]byte, 0, dnsMaxUDPLen)
+	message = appendUint16(message, r.id)
+	message = appendUint16(message, flags)
+
+	if r.question != nil {
+		message = appendUint16(message, 1)
+	} else {
+		message = appendUint16(message, 0)
+	}
+
+	message = appendUint16(message, uint16(len(r.answers)))
+	message = appendUint16(message, uint16(len(r.authority)))
+	message = appendUint16(message, 0)
+	message = append(message, r.question...)
+
+	for _, record := range append(r.answers, r.authority...) {
+		message = append(message, record...)
+	}
+
+	return message
+}
+
+// dnsRecord returns a resource record in the IN class, whose name is a pointer to the offset provided.
+func dnsRecord(name int, rtype uint16, ttl uint32, rdata []byte) []byte {
+	record := appendUint16(nil, 0xc000|uint16(name))
+	record = appendUint16(record, rtype)
+	record = appendUint16(record, dnsClassIN)
+	record = appendUint32(record, ttl)
+	record = appendUint16(record, uint16(len(rdata)))
+
+	return append(record, rdata...)
+}
+
+// dnsTruncate removes the records from a response and sets its TC flag.
+func dnsTruncate(response []byte) []byte {
+	question := dnsHeaderLen
+
+	if binary.BigEndian.Uint16(response[4:]) == 1 {
+		offset := dnsHeaderLen
+
+		for response[offset] != 0 {
+			offset += 1 + int(response[offset])
+		}
+
+		question = offset + 5
+	}
+
+	truncated := append([]byte{}, response[:question]...)
+	truncated[2] |= 0x02
+	copy(truncated[6:dnsHeaderLen], make([]byte, 6))
+
+	return truncated
+}
+
+func appendUint16(b []byte, value uint16) []byte {
+	return append(b, byte(value>>8), byte(value))
+}
@@ -0,0 +1,140 @@
+package abuseipdb
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testDNSBLServer(t *testing.T) *DNSBLServer {
+	set := NewBlacklistSet(testExportBlacklist())
+
+	if err := set.AddNetwork("192.0.2.0/24", 30); err != nil {
+		t.Logf("BlacklistSet.AddNetwork: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	return NewDNSBLServer("bl.example.com.", func() *BlacklistSet { return set }, DNSBLMinScore(25))
+}
+
+func testDNSBLResolver(network string, address string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, address)
+		},
+	}
+}
+
+func TestDNSBLServer(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Logf("net.ListenPacket: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	defer conn.Close()
+
+	go testDNSBLServer(t).ServePacket(conn)
+
+	resolver := testDNSBLResolver("udp", conn.LocalAddr().String())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, test := range []struct {
+		name     string
+		expected []string
+	}{
+		{"1.1.1.1.bl.example.com.", []string{"127.0.0.5"}},
+		{"8.8.8.8.BL.example.com.", []string{"127.0.0.4"}},
+		{"7.2.0.192.bl.example.com.", []string{"127.0.0.3"}},
+		{"2.0.0.127.bl.example.com.", []string{"127.0.0.5"}},
+		{"1.1.1.1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.7.4.6.0.6.2.bl.example.com.", []string{"127.0.0.5"}},
+	} {
+		addresses, err := resolver.LookupHost(ctx, test.name)
+
+		if err != nil || strings.Join(addresses, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("DNSBLServer: expected %s to resolve to %v, got %v (err %v)", test.name, test.expected, addresses, err)
+		}
+	}
+
+	for _, name := range []string{"9.9.9.9.bl.example.com.", "4.4.8.8.8.bl.example.com.", "08.8.8.8.bl.example.com."} {
+		_, err := resolver.LookupHost(ctx, name)
+
+		if dnsError, ok := err.(*net.DNSError); !ok || !dnsError.IsNotFound {
+			t.Errorf("DNSBLServer: expected %s not to be found, got %v", name, err)
+		}
+	}
+
+	records, err := resolver.LookupTXT(ctx, "1.1.1.1.bl.example.com.")
+
+	if err != nil || len(records) != 1 || records[0] != "Listed by AbuseIPDB with an abuse confidence score of 100: https://www.abuseipdb.com/check/1.1.1.1" {
+		t.Errorf("DNSBLServer: expected a TXT record linking to 1.1.1.1, got %v (err %v)", records, err)
+	}
+
+	if _, err := resolver.LookupHost(ctx, "1.1.1.1.example.org."); err == nil {
+		t.Errorf("DNSBLServer: expected queries outside the zone to be refused")
+	}
+}
+
+func TestDNSBLServer_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Logf("net.Listen: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	defer listener.Close()
+
+	go testDNSBLServer(t).Serve(listener)
+
+	resolver := testDNSBLResolver("tcp", listener.Addr().String())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	records, err := resolver.LookupTXT(ctx, "1.1.1.1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.7.4.6.0.6.2.bl.example.com.")
+
+	if err != nil || len(records) != 1 || !strings.HasSuffix(records[0], "https://www.abuseipdb.com/check/2606:4700::1111") {
+		t.Errorf("DNSBLServer: expected a TXT record linking to 2606:4700::1111, got %v (err %v)", records, err)
+	}
+}
+
+func TestDNSBLServer_Unavailable(t *testing.T) {
+	server := NewDNSBLServer("bl.example.com", func() *BlacklistSet { return nil })
+	query := []byte{0x12, 0x34, 0x01, 0x00, 0, 1, 0, 0, 0, 0, 0, 0}
+	query = append(query, 1, '1', 1, '1', 1, '1', 1, '1', 2, 'b', 'l', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, 0, 1, 0, 1)
+	response := server.answer(query)
+
+	if len(response) != len(query) || response[0] != 0x12 || response[1] != 0x34 || response[2] != 0x81 || response[3] != 0x02 {
+		t.Errorf("DNSBLServer: expected SERVFAIL before the blacklist is loaded, got %v", response)
+	}
+
+	if server.answer(response) != nil {
+		t.Errorf("DNSBLServer: expected responses to be ignored")
+	}
+}
+
+func TestDNSBLServer_Serial(t *testing.T) {
+	server := NewDNSBLServer("bl.example.com", func() *BlacklistSet { return NewBlacklistSet(nil) })
+	query := []byte{0x12, 0x34, 0x01, 0x00, 0, 1, 0, 0, 0, 0, 0, 0}
+	query = append(query, 2, 'b', 'l', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', 3, 'c', 'o', 'm', 0, 0, 6, 0, 1)
+	before := time.Now().Unix()
+	response := server.answer(query)
+
+	if len(response) < 20 || response[7] != 1 {
+		t.Logf("DNSBLServer: expected an SOA answer for the zone apex, got %v", response)
+		t.FailNow()
+	}
+
+	// The serial is the first of the five numbers ending the SOA record.
+	serial := int64(binary.BigEndian.Uint32(response[len(response)-20:]))
+
+	if serial < before || serial > time.Now().Unix() {
+		t.Errorf("DNSBLServer: expected the serial to be the current time without a generatedAt, got %d", serial)
+	}
+}
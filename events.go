@@ -0,0 +1,100 @@
+package abuseipdb
+
+import (
+	"time"
+)
+
+// EventType identifies the call to the API an Event was emitted for.
+type EventType int
+
+// A list of the types of event emitted to a client's hooks.
+const (
+	// EventCheck is emitted when an IP is checked with Check.
+	EventCheck EventType = iota + 1
+	// EventReport is emitted when an IP is reported with Report.
+	EventReport
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventCheck:
+		return "check"
+	case EventReport:
+		return "report"
+	default:
+		return "unknown"
+	}
+}
+
+// Event represents a call to the API made by a Client, which is passed to each of its Hooks.
+type Event struct {
+	Type      EventType
+	Time      time.Time
+	IPAddress string
+	// Check is the response for EventCheck events.
+	Check *CheckResponse
+	// Report is the response for EventReport events.
+	Report *ReportResponse
+	// Categories are the categories the IP was reported for, for EventReport events.
+	Categories []Category
+	// Err is set if the call failed, in which case there is no response.
+	Err error
+}
+
+// EventHook is a function which is called with each Event emitted by a Client.
+// Hooks may be called concurrently, and must not modify the event.
+type EventHook func(Event)
+
+// AbuseConfidenceScore returns the abuse confidence score of the IP in the event's response, or 0 if there isn't one.
+func (e Event) AbuseConfidenceScore() int {
+	switch {
+	case e.Check != nil:
+		return e.Check.Data.AbuseConfidenceScore
+	case e.Report != nil:
+		return e.Report.Data.AbuseConfidenceScore
+	default:
+		return 0
+	}
+}
+
+// ReportedCategories returns the categories in the event: those the IP was reported for in EventReport events,
+// or those in the reports included with the response to EventCheck events, which requires the Verbose option.
+// Each category is only listed once, in the order they were first seen.
+func (e Event) ReportedCategories() []Category {
+	seen := make(map[Category]bool)
+	var categories []Category
+
+	add := func(category Category) {
+		if !seen[category] {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+
+	for _, category := range e.Categories {
+		add(category)
+	}
+
+	if e.Check != nil {
+		for _, report := range e.Check.Data.Reports {
+			for _, category := range report.Categories {
+				add(Category(category))
+			}
+		}
+	}
+
+	return categories
+}
+
+// emit calls each of the client's hooks with the event.
+func (c *Client) emit(event Event) {
+	if len(c.Hooks) == 0 {
+		return
+	}
+
+	event.Time = time.Now()
+
+	for _, hook := range c.Hooks {
+		hook(event)
+	}
+}
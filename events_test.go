@@ -0,0 +1,55 @@
+package abuseipdb
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestClient_Hooks(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/check":
+			fmt.Fprint(w, `{"data":{"ipAddress":"1.1.1.1","abuseConfidenceScore":80,"reports":[{"categories":[22,18]},{"categories":[22]}]}}`)
+		case "/report":
+			fmt.Fprint(w, `{"data":{"ipAddress":"1.1.1.1","abuseConfidenceScore":85}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	var (
+		mu     sync.Mutex
+		events []Event
+	)
+
+	client.Hooks = append(client.Hooks, func(event Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		events = append(events, event)
+	})
+
+	_, _ = client.Check("1.1.1.1")
+	_, _ = client.Report("1.1.1.1", []Category{CategorySSH, CategoryBruteForce})
+	_, _ = client.Check("127.0.0.1")
+
+	if len(events) != 2 {
+		t.Logf("Client.Hooks: expected 2 events for the calls which reached the API, got %d", len(events))
+		t.FailNow()
+	}
+
+	if events[0].Type != EventCheck || events[0].Check == nil || events[0].AbuseConfidenceScore() != 80 || events[0].Time.IsZero() {
+		t.Errorf("Client.Hooks: expected a check event with score 80, got %+v", events[0])
+	}
+
+	if categories := events[0].ReportedCategories(); !reflect.DeepEqual(categories, []Category{CategorySSH, CategoryBruteForce}) {
+		t.Errorf("Event.ReportedCategories: expected [SSH BruteForce], got %v", categories)
+	}
+
+	if events[1].Type != EventReport || events[1].AbuseConfidenceScore() != 85 || len(events[1].Categories) != 2 {
+		t.Errorf("Client.Hooks: expected a report event with score 85 and 2 categories, got %+v", events[1])
+	}
+}
@@ -0,0 +1,217 @@
+package abuseipdb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// defaultExportName is the name given to the sets, tables and chains written by the exporters.
+const defaultExportName = "abuseipdb"
+
+type exportConfig struct {
+	minScore       int
+	name           string
+	timeout        func(BlacklistEntry) time.Duration
+	iptablesTarget string
+	ruleCategory   Category
+	rpzAction      RPZAction
+	rpzCNAME       string
+}
+
+var defaultExportConfig = exportConfig{
+	minScore:       0,
+	name:           defaultExportName,
+	timeout:        nil,
+	iptablesTarget: "DROP",
+	ruleCategory:   0,
+	rpzAction:      RPZNXDomain,
+	rpzCNAME:       "",
+}
+
+// ExportOption sets an optional parameter for the blacklist exporters.
+type ExportOption func(*exportConfig)
+
+// ExportMinScore returns an ExportOption that leaves out entries with an abuse confidence score below the value provided.
+// The default value is 0, which exports every entry.
+func ExportMinScore(score int) ExportOption {
+	return func(config *exportConfig) {
+		config.minScore = score
+	}
+}
+
+// ExportName returns an ExportOption that sets the name of the sets, tables or chains being written.
+// Where addresses are split by family, the suffixes "_v4" and "_v6" are added. The default name is "abuseipdb".
+func ExportName(name string) ExportOption {
+	return func(config *exportConfig) {
+		config.name = name
+	}
+}
+
+// ExportTimeout returns an ExportOption that sets how long each entry stays in the set before it expires,
+// for formats which support it. Timeouts are rounded down to the second. By default, entries don't expire.
+func ExportTimeout(timeout time.Duration) ExportOption {
+	return ExportTimeoutFunc(func(BlacklistEntry) time.Duration {
+		return timeout
+	})
+}
+
+// ExportTimeoutFunc returns an ExportOption that sets how long each entry stays in the set before it expires,
+// using a function so the timeout can depend on the entry, such as by its abuse confidence score.
+// A timeout of zero means the entry doesn't expire.
+func ExportTimeoutFunc(timeout func(entry BlacklistEntry) time.Duration) ExportOption {
+	return func(config *exportConfig) {
+		config.timeout = timeout
+	}
+}
+
+// IptablesTarget returns an ExportOption that sets the target of the rules written by WriteIptablesRestore
+// and WriteIp6tablesRestore. The default target is DROP.
+func IptablesTarget(target string) ExportOption {
+	return func(config *exportConfig) {
+		config.iptablesTarget = target
+	}
+}
+
+// RuleCategory returns an ExportOption that sets the abuse category the rules written by WriteIDSRules are for.
+// The category is named in the message of each rule and is used with the address to derive its SID, so rule files
+// written for different categories can be loaded together. By default, rules aren't for a particular category.
+func RuleCategory(category Category) ExportOption {
+	return func(config *exportConfig) {
+		config.ruleCategory = category
+	}
+}
+
+// RPZPolicy returns an ExportOption that sets the action of the rules written by WriteRPZ. The default action is
+// RPZNXDomain.
+func RPZPolicy(action RPZAction) ExportOption {
+	return func(config *exportConfig) {
+		config.rpzAction = action
+		config.rpzCNAME = ""
+	}
+}
+
+// RPZCNAME returns an ExportOption that makes the rules written by WriteRPZ answer with a CNAME to the target
+// provided instead, such as a local host serving a block page.
+func RPZCNAME(target string) ExportOption {
+	return func(config *exportConfig) {
+		config.rpzCNAME = target
+	}
+}
+
+func newExportConfig(options []ExportOption) exportConfig {
+	config := defaultExportConfig
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	return config
+}
+
+// entryTimeout returns the timeout for an entry in whole seconds, or 0 if it doesn't expire.
+func (c exportConfig) entryTimeout(entry BlacklistEntry) int64 {
+	if c.timeout == nil {
+		return 0
+	}
+
+	seconds := int64(c.timeout(entry) / time.Second)
+
+	if seconds < 0 {
+		return 0
+	}
+
+	return seconds
+}
+
+// familyName returns the name of the set for an address family.
+func (c exportConfig) familyName(ipv6 bool) string {
+	if ipv6 {
+		return c.name + "_v6"
+	}
+
+	return c.name + "_v4"
+}
+
+// exportEntries returns the entries to export from the response, split by address family and sorted by address.
+// Addresses are normalised, entries below the minimum score and addresses which can't be parsed are left out,
+// and only the first entry for each address is kept.
+func exportEntries(response *BlacklistResponse, config exportConfig) (ipv4 []BlacklistEntry, ipv6 []BlacklistEntry) {
+	if response == nil {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(response.Data))
+
+	for _, entry := range response.Data {
+		if entry.AbuseConfidenceScore < config.minScore {
+			continue
+		}
+
+		ip := net.ParseIP(canonicalIP(entry.IPAddress))
+
+		if ip == nil {
+			continue
+		}
+
+		entry.IPAddress = ip.String()
+
+		if seen[entry.IPAddress] {
+			continue
+		}
+
+		seen[entry.IPAddress] = true
+
+		if ip.To4() != nil {
+			ipv4 = append(ipv4, entry)
+		} else {
+			ipv6 = append(ipv6, entry)
+		}
+	}
+
+	sortEntries(ipv4)
+	sortEntries(ipv6)
+
+	return ipv4, ipv6
+}
+
+// exportWriter buffers the output of an exporter, keeping the first error so it only needs to be checked once.
+type exportWriter struct {
+	writer *bufio.Writer
+	err    error
+}
+
+func newExportWriter(w io.Writer) *exportWriter {
+	return &exportWriter{writer: bufio.NewWriter(w)}
+}
+
+func (e *exportWriter) printf(format string, args ...interface{}) {
+	if e.err != nil {
+		return
+	}
+
+	_, e.err = fmt.Fprintf(e.writer, format, args...)
+}
+
+// header writes a comment recording when the blacklist was generated and the minimum score exported,
+// with each line starting with the comment prefix provided.
+func (e *exportWriter) header(prefix string, response *BlacklistResponse, config exportConfig) {
+	generatedAt := time.Time{}
+
+	if response != nil {
+		generatedAt = response.Meta.GeneratedAt
+	}
+
+	e.printf("%s AbuseIPDB blacklist generated at %s\n", prefix, generatedAt.UTC().Format(time.RFC3339))
+	e.printf("%s Minimum abuse confidence score: %d\n", prefix, config.minScore)
+}
+
+func (e *exportWriter) flush() error {
+	if e.err != nil {
+		return e.err
+	}
+
+	return e.writer.Flush()
+}
@@ -0,0 +1,168 @@
+package abuseipdb
+
+import (
+	"io"
+)
+
+// ipsetDefaultMaxElem is the default maximum number of entries in an ipset set.
+const ipsetDefaultMaxElem = 65536
+
+// WriteIPSet writes the blacklist to w as input for "ipset restore", with IPv4 and IPv6 addresses in separate
+// hash:ip sets. The entries are loaded into a temporary set which is then swapped in, so the live sets are replaced
+// in one step and never appear empty. If a timeout is set with ExportTimeout or ExportTimeoutFunc, the sets are
+// created with timeout support and each entry is given its timeout.
+func WriteIPSet(w io.Writer, response *BlacklistResponse, options ...ExportOption) error {
+	config := newExportConfig(options)
+	writer := newExportWriter(w)
+	ipv4, ipv6 := exportEntries(response, config)
+
+	for _, family := range []struct {
+		ipv6    bool
+		entries []BlacklistEntry
+	}{{false, ipv4}, {true, ipv6}} {
+		name := config.familyName(family.ipv6)
+		temp := name + "_tmp"
+		params := "family inet"
+
+		if family.ipv6 {
+			params = "family inet6"
+		}
+
+		if config.timeout != nil {
+			params += " timeout 0"
+		}
+
+		writer.printf("create %s hash:ip %s -exist\n", name, params)
+
+		if len(family.entries) > ipsetDefaultMaxElem {
+			writer.printf("create %s hash:ip %s maxelem %d -exist\n", temp, params, len(family.entries))
+		} else {
+			writer.printf("create %s hash:ip %s -exist\n", temp, params)
+		}
+
+		writer.printf("flush %s\n", temp)
+
+		for _, entry := range family.entries {
+			if timeout := config.entryTimeout(entry); timeout > 0 {
+				writer.printf("add %s %s timeout %d\n", temp, entry.IPAddress, timeout)
+			} else {
+				writer.printf("add %s %s\n", temp, entry.IPAddress)
+			}
+		}
+
+		writer.printf("swap %s %s\n", temp, name)
+		writer.printf("destroy %s\n", temp)
+	}
+
+	return writer.flush()
+}
+
+// WriteNftablesSet writes the blacklist to w as an nftables script for "nft -f", defining an IPv4 and an IPv6 set
+// in an inet table. The table and sets are created if they don't exist, and the sets are flushed before the entries
+// are added; nft applies the script as a single transaction. If a timeout is set with ExportTimeout or
+// ExportTimeoutFunc, the sets are created with the timeout flag and each entry is given its timeout.
+func WriteNftablesSet(w io.Writer, response *BlacklistResponse, options ...ExportOption) error {
+	config := newExportConfig(options)
+	writer := newExportWriter(w)
+	ipv4, ipv6 := exportEntries(response, config)
+
+	writer.header("#", response, config)
+	writer.printf("add table inet %s\n", config.name)
+
+	for _, family := range []struct {
+		ipv6    bool
+		entries []BlacklistEntry
+	}{{false, ipv4}, {true, ipv6}} {
+		name := config.familyName(family.ipv6)
+		setType := "ipv4_addr"
+
+		if family.ipv6 {
+			setType = "ipv6_addr"
+		}
+
+		if config.timeout != nil {
+			writer.printf("add set inet %s %s { type %s; flags timeout; }\n", config.name, name, setType)
+		} else {
+			writer.printf("add set inet %s %s { type %s; }\n", config.name, name, setType)
+		}
+
+		writer.printf("flush set inet %s %s\n", config.name, name)
+
+		if len(family.entries) == 0 {
+			continue
+		}
+
+		writer.printf("add element inet %s %s {\n", config.name, name)
+
+		for i, entry := range family.entries {
+			separator := ","
+
+			if i == len(family.entries)-1 {
+				separator = ""
+			}
+
+			if timeout := config.entryTimeout(entry); timeout > 0 {
+				writer.printf("\t%s timeout %ds%s\n", entry.IPAddress, timeout, separator)
+			} else {
+				writer.printf("\t%s%s\n", entry.IPAddress, separator)
+			}
+		}
+
+		writer.printf("}\n")
+	}
+
+	return writer.flush()
+}
+
+// WriteIptablesRestore writes the IPv4 addresses in the blacklist to w as input for "iptables-restore --noflush",
+// defining a chain in the filter table named after ExportName. The chain is emptied and then given a rule for each
+// address, matching packets from it and sending them to the target set with IptablesTarget. Traffic can then be
+// sent through the chain with a rule such as "-A INPUT -j abuseipdb". Use WriteIp6tablesRestore for IPv6 addresses.
+func WriteIptablesRestore(w io.Writer, response *BlacklistResponse, options ...ExportOption) error {
+	config := newExportConfig(options)
+	ipv4, _ := exportEntries(response, config)
+
+	return writeIptablesRestore(w, response, ipv4, config)
+}
+
+// WriteIp6tablesRestore writes the IPv6 addresses in the blacklist to w as input for "ip6tables-restore --noflush",
+// in the same form as WriteIptablesRestore.
+func WriteIp6tablesRestore(w io.Writer, response *BlacklistResponse, options ...ExportOption) error {
+	config := newExportConfig(options)
+	_, ipv6 := exportEntries(response, config)
+
+	return writeIptablesRestore(w, response, ipv6, config)
+}
+
+func writeIptablesRestore(w io.Writer, response *BlacklistResponse, entries []BlacklistEntry, config exportConfig) error {
+	writer := newExportWriter(w)
+
+	writer.header("#", response, config)
+	writer.printf("*filter\n")
+	writer.printf(":%s - [0:0]\n", config.name)
+
+	for _, entry := range entries {
+		writer.printf("-A %s -s %s -j %s\n", config.name, entry.IPAddress, config.iptablesTarget)
+	}
+
+	writer.printf("COMMIT\n")
+
+	return writer.flush()
+}
+
+// WritePFTable writes the blacklist to w as a pf table file, with one address per line, IPv4 addresses first.
+// The file can be loaded with a table definition such as `table <abuseipdb> persist file "/etc/abuseipdb"`,
+// and reloaded with "pfctl -t abuseipdb -T replace -f /etc/abuseipdb".
+func WritePFTable(w io.Writer, response *BlacklistResponse, options ...ExportOption) error {
+	config := newExportConfig(options)
+	writer := newExportWriter(w)
+	ipv4, ipv6 := exportEntries(response, config)
+
+	writer.header("#", response, config)
+
+	for _, entry := range append(ipv4, ipv6...) {
+		writer.printf("%s\n", entry.IPAddress)
+	}
+
+	return writer.flush()
+}
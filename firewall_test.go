@@ -0,0 +1,136 @@
+package abuseipdb
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func testExportBlacklist() *BlacklistResponse {
+	reported := time.Date(2021, 8, 18, 9, 0, 0, 0, time.UTC)
+
+	return testBlacklist(time.Date(2021, 8, 18, 10, 0, 0, 0, time.UTC),
+		BlacklistEntry{IPAddress: "8.8.8.8", AbuseConfidenceScore: 50, LastReportedAt: reported},
+		BlacklistEntry{IPAddress: "2606:4700:0::1111", AbuseConfidenceScore: 75, LastReportedAt: reported},
+		BlacklistEntry{IPAddress: "1.1.1.1", AbuseConfidenceScore: 100, LastReportedAt: reported},
+		BlacklistEntry{IPAddress: "::ffff:1.1.1.1", AbuseConfidenceScore: 100, LastReportedAt: reported},
+	)
+}
+
+func TestWriteIPSet(t *testing.T) {
+	buffer := bytes.Buffer{}
+	err := WriteIPSet(&buffer, testExportBlacklist(), ExportMinScore(75), ExportTimeoutFunc(func(entry BlacklistEntry) time.Duration {
+		return time.Duration(entry.AbuseConfidenceScore) * time.Minute
+	}))
+
+	if err != nil {
+		t.Logf("WriteIPSet: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	expected := `create abuseipdb_v4 hash:ip family inet timeout 0 -exist
+create abuseipdb_v4_tmp hash:ip family inet timeout 0 -exist
+flush abuseipdb_v4_tmp
+add abuseipdb_v4_tmp 1.1.1.1 timeout 6000
+swap abuseipdb_v4_tmp abuseipdb_v4
+destroy abuseipdb_v4_tmp
+create abuseipdb_v6 hash:ip family inet6 timeout 0 -exist
+create abuseipdb_v6_tmp hash:ip family inet6 timeout 0 -exist
+flush abuseipdb_v6_tmp
+add abuseipdb_v6_tmp 2606:4700::1111 timeout 4500
+swap abuseipdb_v6_tmp abuseipdb_v6
+destroy abuseipdb_v6_tmp
+`
+
+	if buffer.String() != expected {
+		t.Errorf("WriteIPSet: expected %q, got %q", expected, buffer.String())
+	}
+}
+
+func TestWriteNftablesSet(t *testing.T) {
+	buffer := bytes.Buffer{}
+	err := WriteNftablesSet(&buffer, testExportBlacklist(), ExportName("blocklist"))
+
+	if err != nil {
+		t.Logf("WriteNftablesSet: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	expected := `# AbuseIPDB blacklist generated at 2021-08-18T10:00:00Z
+# Minimum abuse confidence score: 0
+add table inet blocklist
+add set inet blocklist blocklist_v4 { type ipv4_addr; }
+flush set inet blocklist blocklist_v4
+add element inet blocklist blocklist_v4 {
+	1.1.1.1,
+	8.8.8.8
+}
+add set inet blocklist blocklist_v6 { type ipv6_addr; }
+flush set inet blocklist blocklist_v6
+add element inet blocklist blocklist_v6 {
+	2606:4700::1111
+}
+`
+
+	if buffer.String() != expected {
+		t.Errorf("WriteNftablesSet: expected %q, got %q", expected, buffer.String())
+	}
+}
+
+func TestWriteIptablesRestore(t *testing.T) {
+	buffer := bytes.Buffer{}
+	err := WriteIptablesRestore(&buffer, testExportBlacklist(), IptablesTarget("REJECT"))
+
+	if err != nil {
+		t.Logf("WriteIptablesRestore: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	expected := `# AbuseIPDB blacklist generated at 2021-08-18T10:00:00Z
+# Minimum abuse confidence score: 0
+*filter
+:abuseipdb - [0:0]
+-A abuseipdb -s 1.1.1.1 -j REJECT
+-A abuseipdb -s 8.8.8.8 -j REJECT
+COMMIT
+`
+
+	if buffer.String() != expected {
+		t.Errorf("WriteIptablesRestore: expected %q, got %q", expected, buffer.String())
+	}
+
+}
+
+func TestWriteIp6tablesRestore(t *testing.T) {
+	buffer := bytes.Buffer{}
+	err := WriteIp6tablesRestore(&buffer, testExportBlacklist())
+
+	if err != nil {
+		t.Logf("WriteIp6tablesRestore: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if !bytes.Contains(buffer.Bytes(), []byte("-A abuseipdb -s 2606:4700::1111 -j DROP\n")) || bytes.Contains(buffer.Bytes(), []byte("1.1.1.1")) {
+		t.Errorf("WriteIp6tablesRestore: expected only IPv6 rules, got %q", buffer.String())
+	}
+}
+
+func TestWritePFTable(t *testing.T) {
+	buffer := bytes.Buffer{}
+	err := WritePFTable(&buffer, testExportBlacklist(), ExportMinScore(60))
+
+	if err != nil {
+		t.Logf("WritePFTable: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	expected := `# AbuseIPDB blacklist generated at 2021-08-18T10:00:00Z
+# Minimum abuse confidence score: 60
+1.1.1.1
+2606:4700::1111
+`
+
+	if buffer.String() != expected {
+		t.Errorf("WritePFTable: expected %q, got %q", expected, buffer.String())
+	}
+}
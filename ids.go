@@ -0,0 +1,111 @@
+package abuseipdb
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+)
+
+// The range of the SIDs given to the rules written by WriteIDSRules, which lies above the SIDs used by the
+// Emerging Threats and Snort rule sets.
+const (
+	idsSIDBase  = 1000000000
+	idsSIDRange = 1 << 30
+)
+
+// idsRepCategory is the ID of the category the addresses written by WriteSuricataIPRep are given.
+const idsRepCategory = 1
+
+// WriteIDSRules writes the blacklist to w as a Snort or Suricata rules file, with a rule for each address which
+// alerts on traffic between it and $HOME_NET in either direction. Each rule's SID is a hash of the address and
+// the category set with RuleCategory, from 1000000000 up, so it stays the same while the address is listed.
+// In the rare case that two addresses hash to the same SID, the later one in address order is rehashed, so its SID
+// can change if the other address is added to or leaves the blacklist. Each rule's priority is derived from the
+// abuse confidence score: 1 from 75, 2 from 50, 3 from 25 and 4 below that.
+func WriteIDSRules(w io.Writer, response *BlacklistResponse, options ...ExportOption) error {
+	config := newExportConfig(options)
+	writer := newExportWriter(w)
+	ipv4, ipv6 := exportEntries(response, config)
+	used := make(map[uint32]bool)
+	subject := "listed"
+
+	if config.ruleCategory != 0 {
+		subject = "listed " + config.ruleCategory.String()
+	}
+
+	writer.header("#", response, config)
+
+	for _, entry := range append(ipv4, ipv6...) {
+		sid := idsSID(entry.IPAddress, config.ruleCategory, 0)
+
+		for attempt := 1; used[sid]; attempt++ {
+			sid = idsSID(entry.IPAddress, config.ruleCategory, attempt)
+		}
+
+		used[sid] = true
+
+		writer.printf("alert ip %s any <> $HOME_NET any (msg:\"AbuseIPDB %s address %s, abuse confidence score %d\"; "+
+			"classtype:bad-unknown; priority:%d; sid:%d; rev:1;)\n",
+			entry.IPAddress, subject, entry.IPAddress, entry.AbuseConfidenceScore, idsPriority(entry.AbuseConfidenceScore), sid)
+	}
+
+	return writer.flush()
+}
+
+// WriteSuricataIPRep writes the blacklist to w as a Suricata IP reputation file, giving each address the category
+// written by WriteSuricataIPRepCategories and its abuse confidence score as its reputation. The file is listed in
+// reputation-files in suricata.yaml, and matched with rules such as
+// `alert ip any any -> any any (msg:"AbuseIPDB listed address"; iprep:any,abuseipdb,>,75; sid:999999999; rev:1;)`,
+// using a SID below those generated by WriteIDSRules.
+func WriteSuricataIPRep(w io.Writer, response *BlacklistResponse, options ...ExportOption) error {
+	config := newExportConfig(options)
+	writer := newExportWriter(w)
+	ipv4, ipv6 := exportEntries(response, config)
+
+	writer.header("#", response, config)
+
+	for _, entry := range append(ipv4, ipv6...) {
+		writer.printf("%s,%d,%d\n", entry.IPAddress, idsRepCategory, entry.AbuseConfidenceScore)
+	}
+
+	return writer.flush()
+}
+
+// WriteSuricataIPRepCategories writes the Suricata IP reputation categories file for WriteSuricataIPRep to w,
+// which is set as reputation-categories-file in suricata.yaml. The category is named after ExportName.
+func WriteSuricataIPRepCategories(w io.Writer, options ...ExportOption) error {
+	config := newExportConfig(options)
+	writer := newExportWriter(w)
+
+	writer.printf("%d,%s,AbuseIPDB blacklist\n", idsRepCategory, config.name)
+
+	return writer.flush()
+}
+
+// idsSID returns the SID for the rule for an address and category. Attempts after the first are salted with the
+// attempt number, giving another SID derived from the address when the first is already taken.
+func idsSID(ipAddress string, category Category, attempt int) uint32 {
+	hash := fnv.New32a()
+
+	if attempt == 0 {
+		fmt.Fprintf(hash, "%s|%d", ipAddress, category)
+	} else {
+		fmt.Fprintf(hash, "%s|%d|%d", ipAddress, category, attempt)
+	}
+
+	return idsSIDBase + hash.Sum32()%idsSIDRange
+}
+
+// idsPriority returns the rule priority for an abuse confidence score, where 1 is the highest.
+func idsPriority(score int) int {
+	switch {
+	case score >= 75:
+		return 1
+	case score >= 50:
+		return 2
+	case score >= 25:
+		return 3
+	default:
+		return 4
+	}
+}
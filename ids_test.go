@@ -0,0 +1,93 @@
+package abuseipdb
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWriteIDSRules(t *testing.T) {
+	buffer := bytes.Buffer{}
+	err := WriteIDSRules(&buffer, testExportBlacklist())
+
+	if err != nil {
+		t.Logf("WriteIDSRules: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+
+	if len(lines) != 5 {
+		t.Logf("WriteIDSRules: expected a header and 3 rules, got %q", buffer.String())
+		t.FailNow()
+	}
+
+	expected := `alert ip 1.1.1.1 any <> $HOME_NET any (msg:"AbuseIPDB listed address 1.1.1.1, abuse confidence score 100"; ` +
+		`classtype:bad-unknown; priority:1; sid:` + fmt.Sprint(idsSID("1.1.1.1", 0, 0)) + `; rev:1;)`
+
+	if lines[2] != expected {
+		t.Errorf("WriteIDSRules: expected %q, got %q", expected, lines[2])
+	}
+
+	if !strings.Contains(lines[3], " 8.8.8.8 ") || !strings.Contains(lines[3], "priority:2;") {
+		t.Errorf("WriteIDSRules: expected a priority 2 rule for 8.8.8.8, got %q", lines[3])
+	}
+
+	if !strings.Contains(lines[4], " 2606:4700::1111 ") || !strings.Contains(lines[4], "priority:1;") {
+		t.Errorf("WriteIDSRules: expected a priority 1 rule for 2606:4700::1111, got %q", lines[4])
+	}
+
+	buffer.Reset()
+	_ = WriteIDSRules(&buffer, testExportBlacklist(), RuleCategory(CategorySSH), ExportMinScore(100))
+
+	if !strings.Contains(buffer.String(), `msg:"AbuseIPDB listed SSH address 1.1.1.1`) ||
+		!strings.Contains(buffer.String(), "sid:"+fmt.Sprint(idsSID("1.1.1.1", CategorySSH, 0))+";") {
+		t.Errorf("WriteIDSRules: expected the SSH rule for 1.1.1.1, got %q", buffer.String())
+	}
+
+	if idsSID("1.1.1.1", 0, 0) == idsSID("1.1.1.1", CategorySSH, 0) || idsSID("1.1.1.1", 0, 0) < idsSIDBase {
+		t.Errorf("idsSID: expected SIDs above %d differing by category", idsSIDBase)
+	}
+}
+
+func TestWriteSuricataIPRep(t *testing.T) {
+	buffer := bytes.Buffer{}
+	err := WriteSuricataIPRep(&buffer, testExportBlacklist(), ExportMinScore(75))
+
+	if err != nil {
+		t.Logf("WriteSuricataIPRep: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	expected := `# AbuseIPDB blacklist generated at 2021-08-18T10:00:00Z
+# Minimum abuse confidence score: 75
+1.1.1.1,1,100
+2606:4700::1111,1,75
+`
+
+	if buffer.String() != expected {
+		t.Errorf("WriteSuricataIPRep: expected %q, got %q", expected, buffer.String())
+	}
+
+	buffer.Reset()
+	_ = WriteSuricataIPRepCategories(&buffer)
+
+	if buffer.String() != "1,abuseipdb,AbuseIPDB blacklist\n" {
+		t.Errorf("WriteSuricataIPRepCategories: expected the abuseipdb category, got %q", buffer.String())
+	}
+}
+
+func TestIDSSID(t *testing.T) {
+	first := idsSID("1.1.1.1", 0, 0)
+
+	if first != idsSID("1.1.1.1", 0, 0) || first == idsSID("1.1.1.1", 0, 1) {
+		t.Errorf("idsSID: expected the SID to depend only on the address, category and attempt")
+	}
+
+	for attempt := 0; attempt < 100; attempt++ {
+		if sid := idsSID("2606:4700::1111", CategorySSH, attempt); sid < idsSIDBase || sid >= idsSIDBase+idsSIDRange {
+			t.Errorf("idsSID: expected SIDs from %d to %d, got %d", idsSIDBase, idsSIDBase+idsSIDRange-1, sid)
+		}
+	}
+}
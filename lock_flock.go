@@ -0,0 +1,31 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package abuseipdb
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile places an advisory lock on a file, blocking until it is acquired.
+// Exclusive locks are held by one process at a time, whereas shared locks may be held by many.
+func lockFile(file *os.File, exclusive bool) error {
+	how := syscall.LOCK_SH
+
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+
+	for {
+		err := syscall.Flock(int(file.Fd()), how)
+
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,15 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package abuseipdb
+
+import "os"
+
+// lockFile is a no-op on platforms without flock, so files are only protected within a single process.
+func lockFile(file *os.File, exclusive bool) error {
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,233 @@
+package abuseipdb
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxRateLimitRetries is the number of times CheckMany retries an IP after the rate limit has been exceeded.
+const maxRateLimitRetries = 3
+
+// CheckManyResult represents the outcome of checking one of the IPs passed to CheckMany or CheckStream.
+// Exactly one of Response and Err is set.
+type CheckManyResult struct {
+	IPAddress string
+	Response  *CheckResponse
+	Err       error
+}
+
+// checker is implemented by Client and CachedClient, so checks made in bulk on a CachedClient go through its cache.
+type checker interface {
+	Check(ipAddress string, options ...CheckOption) (*CheckResponse, error)
+	CheckBlock(subnet string, options ...CheckOption) (*CheckBlockResponse, error)
+}
+
+// CheckMany checks each of the IPs provided, streaming the results on the returned channel as they complete.
+// See CheckStream for details.
+func (c *Client) CheckMany(ctx context.Context, ipAddresses []string, options ...CheckOption) <-chan CheckManyResult {
+	return c.checkStream(ctx, c, sendIPs(ctx, ipAddresses), options)
+}
+
+// sendIPs sends each of the IPs provided on the returned channel, which is closed once they have all been received
+// or the context is cancelled.
+func sendIPs(ctx context.Context, ipAddresses []string) <-chan string {
+	ips := make(chan string)
+
+	go func() {
+		defer close(ips)
+
+		for _, ipAddress := range ipAddresses {
+			select {
+			case ips <- ipAddress:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ips
+}
+
+// CheckStream checks each of the IPs received from the channel provided, streaming the results on the returned
+// channel as they complete. Results are not returned in the order the IPs were received.
+//
+// IPs are normalised and deduplicated, so each address is only checked once. Checks are made concurrently, up to the
+// limit set by the Concurrency option. If the rate limit is exceeded, all checks are paused for the duration the API
+// asks for, before being retried. Errors are reported per IP, and don't stop the remaining IPs from being checked.
+//
+// The returned channel is closed once every IP has been checked, or the context is cancelled.
+// Requests already in flight when the context is cancelled are allowed to complete, but their results are discarded.
+func (c *Client) CheckStream(ctx context.Context, ipAddresses <-chan string, options ...CheckOption) <-chan CheckManyResult {
+	return c.checkStream(ctx, c, ipAddresses, options)
+}
+
+// checkStream implements CheckStream, making each check with the checker provided.
+func (c *Client) checkStream(ctx context.Context, check checker, ipAddresses <-chan string, options []CheckOption) <-chan CheckManyResult {
+	config := defaultCheckConfig
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	if config.concurrency < 1 {
+		config.concurrency = 1
+	}
+
+	results := make(chan CheckManyResult)
+	jobs := make(chan string)
+	gate := &rateLimitGate{}
+
+	var wg sync.WaitGroup
+
+	send := func(result CheckManyResult) bool {
+		select {
+		case results <- result:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	wg.Add(1)
+
+	go func() {
+		defer func() {
+			close(jobs)
+			wg.Done()
+		}()
+
+		seen := make(map[string]bool)
+
+		for {
+			var (
+				ipAddress string
+				ok        bool
+			)
+
+			select {
+			case ipAddress, ok = <-ipAddresses:
+			case <-ctx.Done():
+				return
+			}
+
+			if !ok {
+				return
+			}
+
+			normalized, err := c.normalizeIP(ipAddress)
+
+			if err != nil {
+				normalized = ipAddress
+			}
+
+			if seen[normalized] {
+				continue
+			}
+
+			seen[normalized] = true
+
+			if err != nil {
+				if !send(CheckManyResult{IPAddress: ipAddress, Err: err}) {
+					return
+				}
+
+				continue
+			}
+
+			select {
+			case jobs <- normalized:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < config.concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for ipAddress := range jobs {
+				response, err := checkRateLimited(ctx, check, gate, ipAddress, options)
+
+				if ctx.Err() != nil {
+					return
+				}
+
+				if !send(CheckManyResult{IPAddress: ipAddress, Response: response, Err: err}) {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// checkRateLimited checks an IP, waiting and retrying if the rate limit has been exceeded.
+func checkRateLimited(ctx context.Context, check checker, gate *rateLimitGate, ipAddress string, options []CheckOption) (*CheckResponse, error) {
+	for attempt := 0; ; attempt++ {
+		err := gate.wait(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := check.Check(ipAddress, options...)
+
+		if requestError, ok := err.(RequestError); ok && requestError.StatusCode == http.StatusTooManyRequests {
+			if requestError.RetryAfter > 0 && attempt < maxRateLimitRetries {
+				gate.delay(requestError.RetryAfter)
+
+				continue
+			}
+		}
+
+		return response, err
+	}
+}
+
+// rateLimitGate pauses every worker sharing it until the rate limit has reset.
+type rateLimitGate struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+func (g *rateLimitGate) delay(duration time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if until := time.Now().Add(duration); until.After(g.until) {
+		g.until = until
+	}
+}
+
+func (g *rateLimitGate) wait(ctx context.Context) error {
+	for {
+		g.mu.Lock()
+		remaining := time.Until(g.until)
+		g.mu.Unlock()
+
+		if remaining <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(remaining)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package abuseipdb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_CheckMany(t *testing.T) {
+	var requests, limited int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		ipAddress := r.URL.Query().Get("ipAddress")
+
+		if ipAddress == "9.9.9.9" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"errors":[{"detail":"The ip address must be a valid IPv4 or IPv6 address.","status":422}]}`)
+
+			return
+		}
+
+		// The first request for 8.8.8.8 is rate limited, and should be retried.
+		if ipAddress == "8.8.8.8" && atomic.CompareAndSwapInt32(&limited, 0, 1) {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		fmt.Fprintf(w, `{"data":{"ipAddress":"%s","abuseConfidenceScore":0}}`, ipAddress)
+	})
+
+	ips := []string{"1.1.1.1", "8.8.8.8", "::ffff:1.1.1.1", "9.9.9.9", "10.0.0.1", "1.0.0.1", "1.1.1.1"}
+	results := make(map[string]CheckManyResult)
+
+	for result := range client.CheckMany(context.Background(), ips, Concurrency(2)) {
+		if _, ok := results[result.IPAddress]; ok {
+			t.Errorf(`CheckMany: expected one result for "%s"`, result.IPAddress)
+		}
+
+		results[result.IPAddress] = result
+	}
+
+	if len(results) != 5 {
+		t.Errorf("CheckMany: expected 5 results, got %d", len(results))
+	}
+
+	for _, ipAddress := range []string{"1.1.1.1", "8.8.8.8", "1.0.0.1"} {
+		if result := results[ipAddress]; result.Err != nil || result.Response.Data.IPAddress != ipAddress {
+			t.Errorf(`CheckMany: expected response for "%s", got %v`, ipAddress, result.Err)
+		}
+	}
+
+	if _, ok := results["9.9.9.9"].Err.(RequestError); !ok {
+		t.Errorf("CheckMany: expected RequestError for 9.9.9.9, got %v", results["9.9.9.9"].Err)
+	}
+
+	if _, ok := results["10.0.0.1"].Err.(InvalidAddressError); !ok {
+		t.Errorf("CheckMany: expected InvalidAddressError for 10.0.0.1, got %v", results["10.0.0.1"].Err)
+	}
+
+	if requests != 5 {
+		t.Errorf("CheckMany: expected 5 requests, got %d", requests)
+	}
+}
+
+func TestClient_CheckStreamCancel(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data":{"ipAddress":"%s","abuseConfidenceScore":0}}`, r.URL.Query().Get("ipAddress"))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ips := make(chan string)
+	results := client.CheckStream(ctx, ips)
+
+	ips <- "1.1.1.1"
+	<-results
+	cancel()
+
+	select {
+	case _, ok := <-results:
+		if ok {
+			t.Errorf("CheckStream: expected no further results after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("CheckStream: expected results channel to be closed after cancellation")
+	}
+}
+
+func TestCachedClient_CheckMany(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprintf(w, `{"data":{"ipAddress":"%s","abuseConfidenceScore":0}}`, r.URL.Query().Get("ipAddress"))
+	})
+
+	cachedClient := NewCachedClient(client, NewMemoryCache(10))
+
+	if _, err := cachedClient.Check("1.1.1.1"); err != nil {
+		t.Logf("Check: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	for _, ips := range [][]string{{"1.1.1.1", "8.8.8.8"}, {"8.8.8.8", "1.1.1.1"}} {
+		for result := range cachedClient.CheckMany(context.Background(), ips, Concurrency(2)) {
+			if result.Err != nil || result.Response.Data.IPAddress != result.IPAddress {
+				t.Errorf(`CheckMany: expected response for "%s", got %v`, result.IPAddress, result.Err)
+			}
+		}
+	}
+
+	if requests != 2 {
+		t.Errorf("CheckMany: expected cached IPs not to be requested again, got %d requests", requests)
+	}
+
+	if stats := cachedClient.Stats(); stats.Hits != 3 || stats.Misses != 2 {
+		t.Errorf("CheckMany: expected 3 hits and 2 misses, got %+v", stats)
+	}
+}
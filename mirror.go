@@ -0,0 +1,157 @@
+package abuseipdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BlacklistMirror is an http.Handler serving the blacklist kept by a BlacklistRefresher, so many hosts can download
+// it while only the refresher spends the Blacklist endpoint's quota. The blacklist is served as JSON in the same
+// form as BlacklistResponse, or as one address per line when the plaintext query parameter is set or the client
+// only accepts text/plain. As with the API, the confidenceMinimum and limit query parameters filter the response,
+// but locally, from the blacklist the refresher fetched. Without them, every entry is served.
+// Responses carry an ETag and a Last-Modified time of when the blacklist was generated, so clients can poll with
+// If-None-Match or If-Modified-Since and only download a new blacklist. The handler serves every path it receives.
+// Use NewBlacklistMirror to initialise a new mirror.
+type BlacklistMirror struct {
+	refresher *BlacklistRefresher
+
+	mu       sync.Mutex
+	set      *BlacklistSet
+	response *BlacklistResponse
+}
+
+// NewBlacklistMirror initialises a new mirror for the blacklist kept by the refresher provided.
+// The refresher must be run separately.
+func NewBlacklistMirror(refresher *BlacklistRefresher) *BlacklistMirror {
+	return &BlacklistMirror{refresher: refresher}
+}
+
+// ServeHTTP implements http.Handler.
+func (m *BlacklistMirror) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		m.writeError(w, http.StatusMethodNotAllowed, "Method not allowed.")
+
+		return
+	}
+
+	query := r.URL.Query()
+	confidenceMinimum := 0
+	limit := 0
+
+	if value := query.Get("confidenceMinimum"); value != "" {
+		score, err := strconv.Atoi(value)
+
+		if err != nil || score < 25 || score > 100 {
+			m.writeError(w, http.StatusUnprocessableEntity, "The confidence minimum must be between 25 and 100.")
+
+			return
+		}
+
+		confidenceMinimum = score
+	}
+
+	if value := query.Get("limit"); value != "" {
+		count, err := strconv.Atoi(value)
+
+		if err != nil || count < 1 {
+			m.writeError(w, http.StatusUnprocessableEntity, "The limit must be at least 1.")
+
+			return
+		}
+
+		limit = count
+	}
+
+	response := m.current()
+
+	if response == nil {
+		w.Header().Set("Retry-After", "60")
+		m.writeError(w, http.StatusServiceUnavailable, "The blacklist hasn't been fetched yet.")
+
+		return
+	}
+
+	_, plaintext := query["plaintext"]
+	accept := r.Header.Get("Accept")
+	plaintext = plaintext || strings.Contains(accept, "text/plain") && !strings.Contains(accept, "json")
+
+	entries := make([]BlacklistEntry, 0, len(response.Data))
+
+	for _, entry := range response.Data {
+		if limit > 0 && len(entries) == limit {
+			break
+		}
+
+		if entry.AbuseConfidenceScore >= confidenceMinimum {
+			entries = append(entries, entry)
+		}
+	}
+
+	body := bytes.Buffer{}
+	format := "json"
+
+	if plaintext {
+		format = "plaintext"
+
+		for _, entry := range entries {
+			body.WriteString(entry.IPAddress)
+			body.WriteByte('\n')
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		filtered := BlacklistResponse{Meta: response.Meta, Data: entries}
+
+		if err := json.NewEncoder(&body).Encode(filtered); err != nil {
+			m.writeError(w, http.StatusInternalServerError, "The blacklist couldn't be encoded.")
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	generatedAt := response.Meta.GeneratedAt
+
+	w.Header().Set("ETag", fmt.Sprintf(`"%x-%d-%d-%s"`, generatedAt.UnixNano(), confidenceMinimum, limit, format))
+	w.Header().Set("Vary", "Accept")
+	w.Header().Set("X-Generated-At", generatedAt.UTC().Format("2006-01-02T15:04:05-07:00"))
+
+	http.ServeContent(w, r, "", generatedAt, bytes.NewReader(body.Bytes()))
+}
+
+// current returns the refresher's blacklist as a response, converting each new set only once.
+func (m *BlacklistMirror) current() *BlacklistResponse {
+	set := m.refresher.Current()
+
+	if set == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if set != m.set {
+		m.set = set
+		m.response = set.Response()
+	}
+
+	return m.response
+}
+
+// writeError writes an error in the form returned by the API, so it is read as a RequestError.
+func (m *BlacklistMirror) writeError(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []map[string]interface{}{{"detail": detail, "status": status}},
+	})
+}
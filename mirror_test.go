@@ -0,0 +1,92 @@
+package abuseipdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBlacklistMirror(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testBlacklistJSON)
+	})
+
+	refresher := NewBlacklistRefresher(client)
+	server := httptest.NewServer(NewBlacklistMirror(refresher))
+	defer server.Close()
+
+	get := func(path string, header http.Header) (*http.Response, string) {
+		request, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+
+		for key, values := range header {
+			request.Header[key] = values
+		}
+
+		response, err := http.DefaultClient.Do(request)
+
+		if err != nil {
+			t.Logf("http.Get: expected err to be nil, got %v", err)
+			t.FailNow()
+		}
+
+		defer response.Body.Close()
+
+		body, _ := ioutil.ReadAll(response.Body)
+
+		return response, string(body)
+	}
+
+	if response, _ := get("/blacklist", nil); response.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("BlacklistMirror: expected status 503 before the blacklist is fetched, got %d", response.StatusCode)
+	}
+
+	if err := refresher.Refresh(); err != nil {
+		t.Logf("BlacklistRefresher.Refresh: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	response, body := get("/blacklist?confidenceMinimum=75", nil)
+	blacklist := BlacklistResponse{}
+
+	if err := json.Unmarshal([]byte(body), &blacklist); err != nil || response.StatusCode != http.StatusOK {
+		t.Logf("BlacklistMirror: expected a JSON blacklist, got status %d and %q", response.StatusCode, body)
+		t.FailNow()
+	}
+
+	if len(blacklist.Data) != 2 || blacklist.Data[0].IPAddress != "1.1.1.1" || blacklist.Data[1].IPAddress != "2606:4700::1111" {
+		t.Errorf("BlacklistMirror: expected the entries scoring at least 75, got %+v", blacklist.Data)
+	}
+
+	if response.Header.Get("Last-Modified") != "Wed, 18 Aug 2021 10:00:00 GMT" {
+		t.Errorf("BlacklistMirror: expected Last-Modified to be when the blacklist was generated, got %s", response.Header.Get("Last-Modified"))
+	}
+
+	etag := response.Header.Get("ETag")
+
+	if response, _ := get("/blacklist?confidenceMinimum=75", http.Header{"If-None-Match": {etag}}); response.StatusCode != http.StatusNotModified {
+		t.Errorf("BlacklistMirror: expected status 304 for a matching ETag, got %d", response.StatusCode)
+	}
+
+	if response, _ := get("/blacklist", http.Header{"If-None-Match": {etag}}); response.StatusCode != http.StatusOK {
+		t.Errorf("BlacklistMirror: expected status 200 for the ETag of other parameters, got %d", response.StatusCode)
+	}
+
+	if response, _ := get("/blacklist", http.Header{"If-Modified-Since": {"Wed, 18 Aug 2021 10:00:00 GMT"}}); response.StatusCode != http.StatusNotModified {
+		t.Errorf("BlacklistMirror: expected status 304 for an unmodified blacklist, got %d", response.StatusCode)
+	}
+
+	if _, body := get("/blacklist?limit=2&plaintext", nil); body != "1.1.1.1\n2606:4700::1111\n" {
+		t.Errorf("BlacklistMirror: expected the first two addresses in plaintext, got %q", body)
+	}
+
+	if _, body := get("/blacklist", http.Header{"Accept": {"text/plain"}}); body != "1.1.1.1\n2606:4700::1111\n8.8.8.8\n" {
+		t.Errorf("BlacklistMirror: expected every address in plaintext, got %q", body)
+	}
+
+	if response, _ := get("/blacklist?confidenceMinimum=10", nil); response.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("BlacklistMirror: expected status 422 for an invalid confidence minimum, got %d", response.StatusCode)
+	}
+}
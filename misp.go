@@ -0,0 +1,233 @@
+package abuseipdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mispTimeFormat is the format of the first_seen and last_seen attribute properties.
+const mispTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// A list of the MISP threat levels given to exported events.
+const (
+	mispThreatLevelHigh   = "1"
+	mispThreatLevelMedium = "2"
+	mispThreatLevelLow    = "3"
+)
+
+// MISPEvent represents a MISP event, which can be imported through MISP's event import once written with WriteTo.
+type MISPEvent struct {
+	UUID          string          `json:"uuid"`
+	Info          string          `json:"info"`
+	Date          string          `json:"date"`
+	ThreatLevelID string          `json:"threat_level_id"`
+	Analysis      string          `json:"analysis"`
+	Distribution  string          `json:"distribution"`
+	Published     bool            `json:"published"`
+	Timestamp     string          `json:"timestamp"`
+	Tags          []MISPTag       `json:"Tag,omitempty"`
+	Attributes    []MISPAttribute `json:"Attribute"`
+	categories    []Category
+}
+
+// MISPAttribute represents an attribute of a MISP event.
+type MISPAttribute struct {
+	UUID         string    `json:"uuid"`
+	Type         string    `json:"type"`
+	Category     string    `json:"category"`
+	ToIDS        bool      `json:"to_ids"`
+	Distribution string    `json:"distribution"`
+	Value        string    `json:"value"`
+	Comment      string    `json:"comment"`
+	Timestamp    string    `json:"timestamp"`
+	FirstSeen    string    `json:"first_seen,omitempty"`
+	LastSeen     string    `json:"last_seen,omitempty"`
+	Tags         []MISPTag `json:"Tag,omitempty"`
+}
+
+// MISPTag represents a tag on a MISP event or attribute.
+type MISPTag struct {
+	Name string `json:"name"`
+}
+
+// BlacklistMISPEvent returns a MISP event with an ip-src attribute for each entry in the blacklist, in address order.
+// Entries can be filtered with ExportMinScore. The abuse confidence score is recorded in the comment of each attribute,
+// and the time the IP was last reported as its last_seen.
+func BlacklistMISPEvent(response *BlacklistResponse, options ...ExportOption) *MISPEvent {
+	config := newExportConfig(options)
+	ipv4, ipv6 := exportEntries(response, config)
+	generatedAt := time.Time{}
+
+	if response != nil {
+		generatedAt = response.Meta.GeneratedAt
+	}
+
+	event := newMISPEvent(fmt.Sprintf("AbuseIPDB blacklist generated at %s", generatedAt.UTC().Format(time.RFC3339)), generatedAt)
+
+	for _, entry := range append(ipv4, ipv6...) {
+		event.addAttribute(entry.IPAddress, entry.AbuseConfidenceScore, time.Time{}, entry.LastReportedAt, nil)
+	}
+
+	event.finish()
+
+	return event
+}
+
+// CheckMISPEvent returns a MISP event with an ip-src attribute for each checked IP, with the info provided as its
+// title. Each attribute is tagged with the names of the categories the IP was reported for, and the event with the
+// names of every category reported. The abuse confidence score is recorded in the comment of each attribute,
+// the earliest report as its first_seen and the time the IP was last reported as its last_seen.
+// The categories and earliest report are only known if the IPs were checked with the Verbose option.
+// Nil responses, such as those of failed checks, are left out.
+func CheckMISPEvent(info string, checks ...*CheckResponse) *MISPEvent {
+	date := time.Time{}
+
+	for _, check := range checks {
+		if check != nil && check.Data.LastReportedAt.After(date) {
+			date = check.Data.LastReportedAt
+		}
+	}
+
+	event := newMISPEvent(info, date)
+	seen := make(map[string]bool)
+
+	for _, check := range checks {
+		if check == nil {
+			continue
+		}
+
+		ipAddress := canonicalIP(check.Data.IPAddress)
+
+		if seen[ipAddress] {
+			continue
+		}
+
+		seen[ipAddress] = true
+
+		var firstSeen time.Time
+		var categories []Category
+
+		for _, report := range check.Data.Reports {
+			if firstSeen.IsZero() || report.ReportedAt.Before(firstSeen) {
+				firstSeen = report.ReportedAt
+			}
+
+			for _, category := range report.Categories {
+				categories = append(categories, Category(category))
+			}
+		}
+
+		event.addAttribute(ipAddress, check.Data.AbuseConfidenceScore, firstSeen, check.Data.LastReportedAt, categories)
+	}
+
+	event.finish()
+
+	return event
+}
+
+// newMISPEvent returns an empty event dated at the time provided, or now if the time is unknown.
+func newMISPEvent(info string, date time.Time) *MISPEvent {
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	event := MISPEvent{
+		Info:          info,
+		Date:          date.UTC().Format("2006-01-02"),
+		ThreatLevelID: mispThreatLevelLow,
+		Analysis:      "2",
+		Distribution:  "0",
+		Timestamp:     strconv.FormatInt(date.Unix(), 10),
+		Attributes:    []MISPAttribute{},
+	}
+
+	return &event
+}
+
+func (e *MISPEvent) addAttribute(ipAddress string, score int, firstSeen time.Time, lastSeen time.Time, categories []Category) {
+	attribute := MISPAttribute{
+		UUID:         fmt.Sprintf("%s|%d|%d", ipAddress, lastSeen.Unix(), score),
+		Type:         "ip-src",
+		Category:     "Network activity",
+		ToIDS:        score > 0,
+		Distribution: "5",
+		Value:        ipAddress,
+		Comment:      fmt.Sprintf("AbuseIPDB abuse confidence score: %d", score),
+		Timestamp:    e.Timestamp,
+		Tags:         mispCategoryTags(categories),
+	}
+
+	if !lastSeen.IsZero() {
+		attribute.Timestamp = strconv.FormatInt(lastSeen.Unix(), 10)
+		attribute.LastSeen = lastSeen.UTC().Format(mispTimeFormat)
+	}
+
+	if !firstSeen.IsZero() && !lastSeen.IsZero() && !firstSeen.After(lastSeen) {
+		attribute.FirstSeen = firstSeen.UTC().Format(mispTimeFormat)
+	}
+
+	switch {
+	case score >= 75:
+		e.ThreatLevelID = mispThreatLevelHigh
+	case score >= 25 && e.ThreatLevelID != mispThreatLevelHigh:
+		e.ThreatLevelID = mispThreatLevelMedium
+	}
+
+	e.Attributes = append(e.Attributes, attribute)
+	e.categories = append(e.categories, categories...)
+}
+
+// finish tags the event with every category its attributes are tagged with, and sets its UUID from its attributes,
+// so the same data always produces the same event. Until then, each attribute's UUID field holds a key describing it.
+// MISP requires attribute UUIDs to be unique across the whole instance, so each attribute's UUID is then derived from
+// the event's UUID and its key, and an entry exported in several events is given a different UUID in each.
+func (e *MISPEvent) finish() {
+	keys := []string{e.Info}
+
+	for _, attribute := range e.Attributes {
+		keys = append(keys, attribute.UUID)
+	}
+
+	e.Tags = mispCategoryTags(e.categories)
+	e.UUID = uuid5(stixNamespace, "misp-event|"+strings.Join(keys, "|"))
+
+	for i := range e.Attributes {
+		e.Attributes[i].UUID = uuid5(stixNamespace, "misp-attribute|"+e.UUID+"|"+e.Attributes[i].UUID)
+	}
+}
+
+// mispCategoryTags returns a tag for each distinct category, in category order.
+func mispCategoryTags(categories []Category) []MISPTag {
+	categories = append([]Category(nil), categories...)
+	sort.Slice(categories, func(i, j int) bool { return categories[i] < categories[j] })
+
+	var tags []MISPTag
+
+	for i, category := range categories {
+		if i > 0 && categories[i-1] == category {
+			continue
+		}
+
+		tags = append(tags, MISPTag{Name: fmt.Sprintf("abuseipdb:category=%q", category.String())})
+	}
+
+	return tags
+}
+
+// WriteTo writes the event to w as JSON, in the form accepted by MISP's event import.
+func (e *MISPEvent) WriteTo(w io.Writer) (int64, error) {
+	encoded, err := json.MarshalIndent(map[string]*MISPEvent{"Event": e}, "", "  ")
+
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(append(encoded, '\n'))
+
+	return int64(n), err
+}
@@ -0,0 +1,118 @@
+package abuseipdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBlacklistMISPEvent(t *testing.T) {
+	event := BlacklistMISPEvent(testExportBlacklist(), ExportMinScore(60))
+
+	if event.Date != "2021-08-18" || event.ThreatLevelID != mispThreatLevelHigh || len(event.Attributes) != 2 {
+		t.Logf("BlacklistMISPEvent: expected a high threat event on 2021-08-18 with 2 attributes, got %+v", event)
+		t.FailNow()
+	}
+
+	attribute := event.Attributes[1]
+
+	if attribute.Type != "ip-src" || attribute.Value != "2606:4700::1111" || attribute.Comment != "AbuseIPDB abuse confidence score: 75" {
+		t.Errorf("BlacklistMISPEvent: expected ip-src attribute for 2606:4700::1111 with score 75, got %+v", attribute)
+	}
+
+	if attribute.LastSeen != "2021-08-18T09:00:00.000000Z" || attribute.FirstSeen != "" {
+		t.Errorf("BlacklistMISPEvent: expected last_seen 2021-08-18T09:00:00.000000Z and no first_seen, got %+v", attribute)
+	}
+
+	if again := BlacklistMISPEvent(testExportBlacklist(), ExportMinScore(60)); again.UUID != event.UUID {
+		t.Errorf("BlacklistMISPEvent: expected the same blacklist to produce the same event UUID")
+	}
+}
+
+func TestBlacklistMISPEvent_AttributeUUIDs(t *testing.T) {
+	reported := time.Date(2021, 8, 18, 9, 0, 0, 0, time.UTC)
+	shared := BlacklistEntry{IPAddress: "1.1.1.1", AbuseConfidenceScore: 100, LastReportedAt: reported}
+	first := BlacklistMISPEvent(testBlacklist(time.Date(2021, 8, 18, 10, 0, 0, 0, time.UTC), shared,
+		BlacklistEntry{IPAddress: "8.8.8.8", AbuseConfidenceScore: 50, LastReportedAt: reported}))
+	second := BlacklistMISPEvent(testBlacklist(time.Date(2021, 8, 18, 16, 0, 0, 0, time.UTC), shared,
+		BlacklistEntry{IPAddress: "9.9.9.9", AbuseConfidenceScore: 90, LastReportedAt: reported}))
+
+	check := CheckResponse{}
+	check.Data.IPAddress = "1.1.1.1"
+	check.Data.AbuseConfidenceScore = 100
+	check.Data.LastReportedAt = reported
+	checked := CheckMISPEvent("Checked addresses", &check)
+
+	seen := make(map[string]string)
+
+	for _, event := range []*MISPEvent{first, second, checked} {
+		for _, attribute := range event.Attributes {
+			if other, ok := seen[attribute.UUID]; ok {
+				t.Errorf("MISPEvent: expected attribute UUIDs to be unique across events, %s in %s and %s share %s",
+					attribute.Value, other, event.Info, attribute.UUID)
+			}
+
+			seen[attribute.UUID] = event.Info
+		}
+	}
+
+	if again := BlacklistMISPEvent(testBlacklist(time.Date(2021, 8, 18, 10, 0, 0, 0, time.UTC), shared,
+		BlacklistEntry{IPAddress: "8.8.8.8", AbuseConfidenceScore: 50, LastReportedAt: reported})); again.Attributes[0].UUID != first.Attributes[0].UUID {
+		t.Errorf("BlacklistMISPEvent: expected the same blacklist to produce the same attribute UUIDs")
+	}
+}
+
+func TestCheckMISPEvent(t *testing.T) {
+	check := CheckResponse{}
+	check.Data.IPAddress = "1.1.1.1"
+	check.Data.AbuseConfidenceScore = 40
+	check.Data.LastReportedAt = time.Date(2021, 8, 18, 9, 0, 0, 0, time.UTC)
+	check.Data.Reports = []Report{
+		{ReportedAt: time.Date(2021, 8, 18, 9, 0, 0, 0, time.UTC), Categories: []int{int(CategorySSH), int(CategoryBruteForce)}},
+		{ReportedAt: time.Date(2021, 8, 10, 9, 0, 0, 0, time.UTC), Categories: []int{int(CategorySSH)}},
+	}
+
+	other := CheckResponse{}
+	other.Data.IPAddress = "8.8.8.8"
+	other.Data.Reports = []Report{{Categories: []int{int(CategoryPortScan)}}}
+
+	event := CheckMISPEvent("Suspicious logins", &check, nil, &other, &check)
+
+	if event.Info != "Suspicious logins" || event.ThreatLevelID != mispThreatLevelMedium || len(event.Attributes) != 2 {
+		t.Logf("CheckMISPEvent: expected a medium threat event with 2 attributes, got %+v", event)
+		t.FailNow()
+	}
+
+	attribute := event.Attributes[0]
+
+	if attribute.FirstSeen != "2021-08-10T09:00:00.000000Z" || attribute.LastSeen != "2021-08-18T09:00:00.000000Z" || !attribute.ToIDS {
+		t.Errorf("CheckMISPEvent: expected first_seen and last_seen from the reports, got %+v", attribute)
+	}
+
+	if expected := []MISPTag{{`abuseipdb:category="BruteForce"`}, {`abuseipdb:category="SSH"`}}; !reflect.DeepEqual(attribute.Tags, expected) {
+		t.Errorf("CheckMISPEvent: expected attribute tags %v, got %v", expected, attribute.Tags)
+	}
+
+	if len(event.Tags) != 3 || event.Attributes[1].ToIDS {
+		t.Errorf("CheckMISPEvent: expected 3 event tags and no IDS flag for an unscored IP, got %+v", event)
+	}
+
+	buffer := bytes.Buffer{}
+
+	if _, err := event.WriteTo(&buffer); err != nil {
+		t.Logf("MISPEvent.WriteTo: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	decoded := struct {
+		Event struct {
+			Attribute []map[string]interface{}
+		}
+	}{}
+
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil || len(decoded.Event.Attribute) != 2 {
+		t.Errorf("MISPEvent.WriteTo: expected JSON wrapped in an Event object, got %s", buffer.String())
+	}
+}
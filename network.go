@@ -0,0 +1,178 @@
+package abuseipdb
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// CheckNetwork will return the stored information about a subnet (either v4 or v6) of any size, denoted with CIDR notation.
+// Networks larger than the client's Plan allows for CheckBlock are split into the largest sub-blocks permitted,
+// which are checked concurrently. The results are merged into a single response covering the whole network,
+// in which NumPossibleHosts is the sum over all sub-blocks.
+// Each sub-block uses one request from the daily CheckBlock quota, see MaxBlocks.
+func (c *Client) CheckNetwork(network string, options ...CheckOption) (*CheckBlockResponse, error) {
+	config := defaultCheckBlockConfig
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	if config.concurrency < 1 {
+		return nil, errors.New("concurrency must be greater than 0")
+	}
+
+	ipNet, err := parseCIDR(network)
+
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := splitNetwork(ipNet, c.Plan.MaxBlockPrefix(ipNet.IP.To4() == nil), config.maxBlocks)
+
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*CheckBlockResponse, len(blocks))
+	semaphore := make(chan struct{}, config.concurrency)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		firstErr  error
+		completed int
+	)
+
+	for i, block := range blocks {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+
+		if failed {
+			break
+		}
+
+		semaphore <- struct{}{}
+		wg.Add(1)
+
+		go func(i int, block string) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			response, err := c.CheckBlock(block, MaxAgeInDays(config.maxAgeInDays))
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+
+				return
+			}
+
+			responses[i] = response
+			completed++
+
+			if config.progress != nil {
+				config.progress(completed, len(blocks))
+			}
+		}(i, block.String())
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return mergeBlockResponses(ipNet, responses), nil
+}
+
+// parseCIDR parses a network in CIDR notation, unwrapping IPv4-mapped IPv6 networks to their IPv4 form.
+func parseCIDR(network string) (*net.IPNet, error) {
+	_, ipNet, err := net.ParseCIDR(strings.TrimSpace(network))
+
+	if err != nil {
+		return nil, err
+	}
+
+	ones, bits := ipNet.Mask.Size()
+
+	if ip4 := ipNet.IP.To4(); ip4 != nil && bits == 128 && ones >= 96 {
+		ipNet = &net.IPNet{
+			IP:   ip4,
+			Mask: net.CIDRMask(ones-96, 32),
+		}
+	}
+
+	return ipNet, nil
+}
+
+// splitNetwork divides a network into sub-blocks with the given prefix length.
+// If the network is already small enough, it is returned unchanged.
+func splitNetwork(network *net.IPNet, prefix int, maxBlocks int) ([]*net.IPNet, error) {
+	ones, bits := network.Mask.Size()
+
+	if ones >= prefix {
+		return []*net.IPNet{network}, nil
+	}
+
+	splitBits := uint(prefix - ones)
+
+	if splitBits > 30 || 1<<splitBits > maxBlocks {
+		return nil, fmt.Errorf("network %s would be split into more than %d blocks of /%d", network, maxBlocks, prefix)
+	}
+
+	count := 1 << splitBits
+	blocks := make([]*net.IPNet, 0, count)
+
+	for i := 0; i < count; i++ {
+		ip := make(net.IP, len(network.IP))
+		copy(ip, network.IP)
+
+		for b := uint(0); b < splitBits; b++ {
+			if (i>>b)&1 == 1 {
+				position := uint(prefix) - 1 - b
+				ip[position/8] |= 0x80 >> (position % 8)
+			}
+		}
+
+		blocks = append(blocks, &net.IPNet{
+			IP:   ip,
+			Mask: net.CIDRMask(prefix, bits),
+		})
+	}
+
+	return blocks, nil
+}
+
+func mergeBlockResponses(network *net.IPNet, responses []*CheckBlockResponse) *CheckBlockResponse {
+	merged := CheckBlockResponse{}
+
+	merged.Data.NetworkAddress = network.IP.String()
+	merged.Data.Netmask = net.IP(network.Mask).String()
+	merged.Data.ReportedAddress = []ReportedAddress{}
+
+	for i, response := range responses {
+		if i == 0 {
+			merged.Data.MinAddress = response.Data.MinAddress
+			merged.Data.AddressSpaceDesc = response.Data.AddressSpaceDesc
+		}
+
+		if i == len(responses)-1 {
+			merged.Data.MaxAddress = response.Data.MaxAddress
+		}
+
+		merged.Data.NumPossibleHosts += response.Data.NumPossibleHosts
+		merged.Data.ReportedAddress = append(merged.Data.ReportedAddress, response.Data.ReportedAddress...)
+	}
+
+	return &merged
+}
@@ -0,0 +1,118 @@
+package abuseipdb
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSplitNetwork(t *testing.T) {
+	_, network, _ := net.ParseCIDR("10.1.0.0/22")
+
+	blocks, err := splitNetwork(network, 24, 256)
+
+	if err != nil {
+		t.Logf("splitNetwork: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	expected := []string{"10.1.0.0/24", "10.1.1.0/24", "10.1.2.0/24", "10.1.3.0/24"}
+
+	if len(blocks) != len(expected) {
+		t.Logf("splitNetwork: expected %d blocks, got %d", len(expected), len(blocks))
+		t.FailNow()
+	}
+
+	for i, block := range blocks {
+		if block.String() != expected[i] {
+			t.Errorf(`splitNetwork: expected block %d to be "%s", got "%s"`, i, expected[i], block)
+		}
+	}
+
+	_, network, _ = net.ParseCIDR("10.1.2.0/26")
+
+	blocks, err = splitNetwork(network, 24, 256)
+
+	if err != nil || len(blocks) != 1 || blocks[0].String() != "10.1.2.0/26" {
+		t.Errorf(`splitNetwork: expected "10.1.2.0/26" to be returned unchanged, got %v (%v)`, blocks, err)
+	}
+
+	_, network, _ = net.ParseCIDR("10.0.0.0/8")
+
+	_, err = splitNetwork(network, 24, 256)
+
+	if err == nil {
+		t.Errorf("splitNetwork: expected err to be non-nil when exceeding the maximum number of blocks")
+	}
+}
+
+func TestParseCIDR(t *testing.T) {
+	network, err := parseCIDR(" ::ffff:1.2.3.4/120 ")
+
+	if err != nil {
+		t.Logf("parseCIDR: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if network.String() != "1.2.3.0/24" {
+		t.Errorf(`parseCIDR: expected "1.2.3.0/24", got "%s"`, network)
+	}
+}
+
+func TestClient_CheckNetwork(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		network := r.URL.Query().Get("network")
+		prefix := strings.TrimSuffix(network, "0/24")
+
+		fmt.Fprintf(w, `{"data":{"networkAddress":"%s0","netmask":"255.255.255.0","minAddress":"%s1","maxAddress":"%s254","numPossibleHosts":254,"addressSpaceDesc":"Internet","reportedAddress":[{"ipAddress":"%s7","numReports":3,"abuseConfidenceScore":50,"countryCode":"GB"}]}}`, prefix, prefix, prefix, prefix)
+	})
+
+	var lastCompleted, lastTotal int
+
+	response, err := client.CheckNetwork("8.8.4.0/22", Concurrency(2), Progress(func(completed, total int) {
+		lastCompleted = completed
+		lastTotal = total
+	}))
+
+	if err != nil {
+		t.Logf("CheckNetwork: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if requests != 4 {
+		t.Errorf("CheckNetwork: expected 4 requests, got %d", requests)
+	}
+
+	if lastCompleted != 4 || lastTotal != 4 {
+		t.Errorf("CheckNetwork: expected final progress to be 4/4, got %d/%d", lastCompleted, lastTotal)
+	}
+
+	if response.Data.NetworkAddress != "8.8.4.0" || response.Data.Netmask != "255.255.252.0" {
+		t.Errorf(`CheckNetwork: expected network "8.8.4.0/255.255.252.0", got "%s/%s"`, response.Data.NetworkAddress, response.Data.Netmask)
+	}
+
+	if response.Data.MinAddress != "8.8.4.1" || response.Data.MaxAddress != "8.8.7.254" {
+		t.Errorf(`CheckNetwork: expected range "8.8.4.1-8.8.7.254", got "%s-%s"`, response.Data.MinAddress, response.Data.MaxAddress)
+	}
+
+	if response.Data.NumPossibleHosts != 1016 {
+		t.Errorf("CheckNetwork: expected number of possible hosts to be 1016, got %d", response.Data.NumPossibleHosts)
+	}
+
+	if len(response.Data.ReportedAddress) != 4 || response.Data.ReportedAddress[3].IPAddress != "8.8.7.7" {
+		t.Errorf("CheckNetwork: expected 4 reported addresses ending with 8.8.7.7, got %v", response.Data.ReportedAddress)
+	}
+
+	_, err = client.CheckNetwork("8.8.0.0/15")
+
+	if err == nil {
+		t.Errorf("CheckNetwork: expected err to be non-nil when the network exceeds the maximum number of blocks")
+	}
+}
@@ -0,0 +1,93 @@
+package abuseipdb
+
+// Plan represents an AbuseIPDB subscription plan.
+// See: https://www.abuseipdb.com/pricing
+type Plan int
+
+// A list of the subscription plans offered by AbuseIPDB.
+const (
+	// PlanFree is the plan used by API keys without a subscription.
+	PlanFree Plan = iota
+	// PlanBasic is the Basic subscription plan.
+	PlanBasic
+	// PlanPremium is the Premium subscription plan.
+	PlanPremium
+)
+
+func (p Plan) String() string {
+	switch p {
+	case PlanFree:
+		return "Free"
+	case PlanBasic:
+		return "Basic"
+	case PlanPremium:
+		return "Premium"
+	default:
+		return "Plan(unknown)"
+	}
+}
+
+// MaxBlockPrefix returns the shortest prefix length which can be checked by CheckBlock on the plan.
+// Free users are limited to /24 and smaller, Basic plan users to /20 and smaller and Premium plan users to /16 and smaller.
+// AbuseIPDB doesn't document limits for IPv6, so the same number of host bits is assumed (/120, /116 and /112).
+func (p Plan) MaxBlockPrefix(ipv6 bool) int {
+	prefix := 24
+
+	switch p {
+	case PlanBasic:
+		prefix = 20
+	case PlanPremium:
+		prefix = 16
+	}
+
+	if ipv6 {
+		return prefix + 96
+	}
+
+	return prefix
+}
+
+// CheckRequestsPerDay returns the number of requests which can be made to the Check endpoint each day on the plan.
+func (p Plan) CheckRequestsPerDay() int {
+	switch p {
+	case PlanBasic:
+		return 10000
+	case PlanPremium:
+		return 50000
+	default:
+		return 1000
+	}
+}
+
+// CheckBlockRequestsPerDay returns the number of requests which can be made to the CheckBlock endpoint each day
+// on the plan. This limit is metered separately from, and is much smaller than, the limit for the Check endpoint.
+func (p Plan) CheckBlockRequestsPerDay() int {
+	switch p {
+	case PlanBasic:
+		return 1000
+	case PlanPremium:
+		return 5000
+	default:
+		return 100
+	}
+}
+
+// checkBlockCost returns how many Check requests use the same share of the plan's daily quota as one CheckBlock
+// request, rounded up.
+func (p Plan) checkBlockCost() int {
+	checks, checkBlocks := p.CheckRequestsPerDay(), p.CheckBlockRequestsPerDay()
+
+	return (checks + checkBlocks - 1) / checkBlocks
+}
+
+// BlacklistRequestsPerDay returns the number of requests which can be made to the Blacklist endpoint each day on the plan.
+func (p Plan) BlacklistRequestsPerDay() int {
+	switch p {
+	case PlanBasic:
+		return 10
+	case PlanPremium:
+		return 500
+	default:
+		return 5
+	}
+}
@@ -0,0 +1,47 @@
+package abuseipdb
+
+import "testing"
+
+func TestPlan_MaxBlockPrefix(t *testing.T) {
+	tests := []struct {
+		plan     Plan
+		ipv6     bool
+		expected int
+	}{
+		{PlanFree, false, 24},
+		{PlanBasic, false, 20},
+		{PlanPremium, false, 16},
+		{PlanFree, true, 120},
+		{PlanPremium, true, 112},
+	}
+
+	for _, test := range tests {
+		got := test.plan.MaxBlockPrefix(test.ipv6)
+
+		if got != test.expected {
+			t.Errorf("Plan.MaxBlockPrefix: expected %d for %s (ipv6: %t), got %d", test.expected, test.plan, test.ipv6, got)
+		}
+	}
+}
+
+func TestPlan_BlacklistRequestsPerDay(t *testing.T) {
+	if got := PlanFree.BlacklistRequestsPerDay(); got != 5 {
+		t.Errorf("Plan.BlacklistRequestsPerDay: expected 5 for Free, got %d", got)
+	}
+
+	if got := PlanPremium.BlacklistRequestsPerDay(); got != 500 {
+		t.Errorf("Plan.BlacklistRequestsPerDay: expected 500 for Premium, got %d", got)
+	}
+}
+
+func TestPlan_CheckBlockCost(t *testing.T) {
+	for _, plan := range []Plan{PlanFree, PlanBasic, PlanPremium} {
+		if got := plan.checkBlockCost(); got != 10 {
+			t.Errorf("Plan.checkBlockCost: expected 10 for %s, got %d", plan, got)
+		}
+	}
+
+	if got := PlanFree.CheckBlockRequestsPerDay(); got != 100 {
+		t.Errorf("Plan.CheckBlockRequestsPerDay: expected 100 for Free, got %d", got)
+	}
+}
@@ -0,0 +1,313 @@
+package abuseipdb
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// LookupSource identifies which endpoint the data in an Assessment came from.
+type LookupSource int
+
+// A list of the sources an Assessment may come from.
+const (
+	// SourceCheck means the data came from a call to Check, and the full response is available.
+	SourceCheck LookupSource = iota + 1
+	// SourceCheckBlock means the data came from the ReportedAddress list of a call to CheckBlock,
+	// so only the abuse confidence score, number of reports, country code and most recent report are known.
+	SourceCheckBlock
+)
+
+func (s LookupSource) String() string {
+	switch s {
+	case SourceCheck:
+		return "check"
+	case SourceCheckBlock:
+		return "check-block"
+	default:
+		return "unknown"
+	}
+}
+
+// Assessment represents the abuse data for a single IP assessed by Assess or ExecutePlan.
+type Assessment struct {
+	IPAddress            string
+	Source               LookupSource
+	AbuseConfidenceScore int
+	NumReports           int
+	CountryCode          string
+	LastReportedAt       time.Time
+	// Check is the full response for the IP, which is only set when the Source is SourceCheck.
+	Check *CheckResponse
+	// Err is set if the IP couldn't be assessed, in which case the other fields are empty.
+	Err error
+}
+
+// LookupPlan represents the requests needed to assess a set of IPs using the least quota.
+type LookupPlan struct {
+	// Blocks lists the networks to check with CheckBlock, each covering several of the IPs.
+	Blocks []PlannedBlock
+	// Checks lists the IPs to check individually with Check.
+	Checks []string
+	// Invalid lists the IPs which can't be checked, with the reason why.
+	Invalid map[string]error
+	// order records the normalised IPs in the order they were provided.
+	order []string
+	// plan is the subscription plan the lookups were planned for.
+	plan Plan
+}
+
+// PlannedBlock represents a network to check with CheckBlock, and the IPs in it which are being assessed.
+type PlannedBlock struct {
+	Network     string
+	IPAddresses []string
+}
+
+// Requests returns the number of requests needed to carry out the plan.
+func (p *LookupPlan) Requests() int {
+	return len(p.Blocks) + len(p.Checks)
+}
+
+// Cost returns the share of the daily quota needed to carry out the plan, as a number of Check requests.
+// Each call to CheckBlock is weighted by how much smaller its daily limit is than the limit for Check,
+// so on the Free plan, where Check allows 1,000 requests a day and CheckBlock 100, it costs 10.
+func (p *LookupPlan) Cost() int {
+	return len(p.Blocks)*p.plan.checkBlockCost() + len(p.Checks)
+}
+
+// BlockThreshold returns a CheckOption that sets the minimum number of IPs which must fall into the same network
+// before PlanLookups uses CheckBlock for them instead of checking each one individually.
+// The default value is 0, which uses CheckBlock only when it costs less of the client's Plan's daily quota than
+// checking each IP: for more than 10 IPs on every current plan. Check is preferred when the cost is the same,
+// as it returns the full response for each IP.
+func BlockThreshold(ips int) CheckOption {
+	return func(config *checkConfig) {
+		config.blockThreshold = ips
+	}
+}
+
+// PlanLookups works out which calls to Check and CheckBlock are needed to assess the IPs provided with the least quota.
+// IPs are grouped into the largest networks the client's Plan allows CheckBlock to be used on, and each group with at
+// least as many IPs as the BlockThreshold is covered by a single call to CheckBlock. As CheckBlock has a much smaller
+// daily limit than Check, by default it is only used for groups large enough to save quota overall.
+// IPs are normalised and deduplicated, and any which can't be checked are listed in LookupPlan.Invalid.
+func (c *Client) PlanLookups(ipAddresses []string, options ...CheckOption) (*LookupPlan, error) {
+	config := defaultCheckConfig
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	threshold, err := blockThreshold(config, c.Plan)
+
+	if err != nil {
+		return nil, err
+	}
+
+	plan := LookupPlan{
+		Invalid: make(map[string]error),
+		plan:    c.Plan,
+	}
+
+	groups := make(map[string][]string)
+	seen := make(map[string]bool)
+	var networks []string
+
+	for _, ipAddress := range ipAddresses {
+		normalized, err := c.normalizeIP(ipAddress)
+
+		if err != nil {
+			if _, ok := plan.Invalid[ipAddress]; !ok {
+				plan.Invalid[ipAddress] = err
+				plan.order = append(plan.order, ipAddress)
+			}
+
+			continue
+		}
+
+		if seen[normalized] {
+			continue
+		}
+
+		seen[normalized] = true
+
+		ip := net.ParseIP(normalized)
+		mask := net.CIDRMask(c.Plan.MaxBlockPrefix(true), 128)
+
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+			mask = net.CIDRMask(c.Plan.MaxBlockPrefix(false), 32)
+		}
+
+		network := (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String()
+
+		if _, ok := groups[network]; !ok {
+			networks = append(networks, network)
+		}
+
+		groups[network] = append(groups[network], normalized)
+		plan.order = append(plan.order, normalized)
+	}
+
+	for _, network := range networks {
+		if len(groups[network]) >= threshold {
+			plan.Blocks = append(plan.Blocks, PlannedBlock{
+				Network:     network,
+				IPAddresses: groups[network],
+			})
+		} else {
+			plan.Checks = append(plan.Checks, groups[network]...)
+		}
+	}
+
+	return &plan, nil
+}
+
+// blockThreshold returns the BlockThreshold set in the config, or the default for the Plan provided.
+func blockThreshold(config checkConfig, plan Plan) (int, error) {
+	if config.blockThreshold < 0 {
+		return 0, errors.New("blockThreshold must not be negative")
+	}
+
+	if config.blockThreshold == 0 {
+		return plan.checkBlockCost() + 1, nil
+	}
+
+	return config.blockThreshold, nil
+}
+
+// Assess returns the abuse data for each of the IPs provided, using the least quota possible.
+// See PlanLookups for how the requests are chosen, and ExecutePlan for how they are made.
+func (c *Client) Assess(ipAddresses []string, options ...CheckOption) ([]Assessment, error) {
+	plan, err := c.PlanLookups(ipAddresses, options...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ExecutePlan(plan, options...)
+}
+
+// ExecutePlan makes the requests in a LookupPlan concurrently, up to the limit set by the Concurrency option,
+// and returns an Assessment for each IP in the order they were planned.
+// If a request fails, the error is reported in the Assessment of every IP it covered.
+func (c *Client) ExecutePlan(plan *LookupPlan, options ...CheckOption) ([]Assessment, error) {
+	return executePlan(c, plan, options)
+}
+
+// executePlan implements ExecutePlan, making each request with the checker provided.
+func executePlan(check checker, plan *LookupPlan, options []CheckOption) ([]Assessment, error) {
+	config := defaultCheckConfig
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	if config.concurrency < 1 {
+		return nil, errors.New("concurrency must be greater than 0")
+	}
+
+	var (
+		wg          sync.WaitGroup
+		mu          sync.Mutex
+		assessments = make(map[string]Assessment)
+		semaphore   = make(chan struct{}, config.concurrency)
+	)
+
+	for ipAddress, err := range plan.Invalid {
+		assessments[ipAddress] = Assessment{IPAddress: ipAddress, Err: err}
+	}
+
+	for _, block := range plan.Blocks {
+		semaphore <- struct{}{}
+		wg.Add(1)
+
+		go func(block PlannedBlock) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			response, err := check.CheckBlock(block.Network, options...)
+			reported := make(map[string]ReportedAddress)
+
+			if err == nil {
+				for _, address := range response.Data.ReportedAddress {
+					reported[canonicalIP(address.IPAddress)] = address
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			for _, ipAddress := range block.IPAddresses {
+				assessments[ipAddress] = blockAssessment(ipAddress, reported, err)
+			}
+		}(block)
+	}
+
+	for _, ipAddress := range plan.Checks {
+		semaphore <- struct{}{}
+		wg.Add(1)
+
+		go func(ipAddress string) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			response, err := check.Check(ipAddress, options...)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			assessments[ipAddress] = checkAssessment(ipAddress, response, err)
+		}(ipAddress)
+	}
+
+	wg.Wait()
+
+	results := make([]Assessment, 0, len(plan.order))
+
+	for _, ipAddress := range plan.order {
+		results = append(results, assessments[ipAddress])
+	}
+
+	return results, nil
+}
+
+func checkAssessment(ipAddress string, response *CheckResponse, err error) Assessment {
+	if err != nil {
+		return Assessment{IPAddress: ipAddress, Err: err}
+	}
+
+	return Assessment{
+		IPAddress:            ipAddress,
+		Source:               SourceCheck,
+		AbuseConfidenceScore: response.Data.AbuseConfidenceScore,
+		NumReports:           response.Data.TotalReports,
+		CountryCode:          response.Data.CountryCode,
+		LastReportedAt:       response.Data.LastReportedAt,
+		Check:                response,
+	}
+}
+
+// blockAssessment looks up an IP in the ReportedAddress list of a CheckBlock response.
+// IPs which aren't listed haven't been reported, so have a score of zero.
+func blockAssessment(ipAddress string, reported map[string]ReportedAddress, err error) Assessment {
+	if err != nil {
+		return Assessment{IPAddress: ipAddress, Err: err}
+	}
+
+	address := reported[ipAddress]
+
+	return Assessment{
+		IPAddress:            ipAddress,
+		Source:               SourceCheckBlock,
+		AbuseConfidenceScore: address.AbuseConfidenceScore,
+		NumReports:           address.NumReports,
+		CountryCode:          address.CountryCode,
+		LastReportedAt:       address.MostRecentReport,
+	}
+}
@@ -0,0 +1,191 @@
+package abuseipdb
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_PlanLookups(t *testing.T) {
+	client := NewClient("")
+
+	plan, err := client.PlanLookups([]string{"1.1.1.1", "1.1.1.2", "::ffff:1.1.1.3", "1.1.1.1", "8.8.8.8", "192.168.0.1"}, BlockThreshold(2))
+
+	if err != nil {
+		t.Logf("PlanLookups: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if len(plan.Blocks) != 1 || plan.Blocks[0].Network != "1.1.1.0/24" || len(plan.Blocks[0].IPAddresses) != 3 {
+		t.Errorf(`PlanLookups: expected one block "1.1.1.0/24" covering 3 IPs, got %v`, plan.Blocks)
+	}
+
+	if len(plan.Checks) != 1 || plan.Checks[0] != "8.8.8.8" {
+		t.Errorf(`PlanLookups: expected checks to be ["8.8.8.8"], got %v`, plan.Checks)
+	}
+
+	if _, ok := plan.Invalid["192.168.0.1"]; !ok || len(plan.Invalid) != 1 {
+		t.Errorf(`PlanLookups: expected "192.168.0.1" to be invalid, got %v`, plan.Invalid)
+	}
+
+	if plan.Requests() != 2 {
+		t.Errorf("LookupPlan.Requests: expected 2, got %d", plan.Requests())
+	}
+
+	if plan.Cost() != 11 {
+		t.Errorf("LookupPlan.Cost: expected a check-block to cost 10 checks on the Free plan, got %d", plan.Cost())
+	}
+
+	plan, _ = client.PlanLookups([]string{"1.1.1.1", "1.1.1.2"}, BlockThreshold(3))
+
+	if len(plan.Blocks) != 0 || len(plan.Checks) != 2 {
+		t.Errorf("PlanLookups: expected 2 checks below the block threshold, got %d blocks and %d checks", len(plan.Blocks), len(plan.Checks))
+	}
+
+	client.Plan = PlanBasic
+	plan, _ = client.PlanLookups([]string{"1.1.1.1", "1.1.2.1"}, BlockThreshold(2))
+
+	if len(plan.Blocks) != 1 || plan.Blocks[0].Network != "1.1.0.0/20" {
+		t.Errorf(`PlanLookups: expected one block "1.1.0.0/20" on the Basic plan, got %v`, plan.Blocks)
+	}
+}
+
+func TestClient_PlanLookups_DefaultThreshold(t *testing.T) {
+	client := NewClient("")
+
+	var ipAddresses []string
+
+	for i := 1; i <= 11; i++ {
+		ipAddresses = append(ipAddresses, fmt.Sprintf("1.1.1.%d", i))
+	}
+
+	plan, err := client.PlanLookups(ipAddresses[:10])
+
+	if err != nil {
+		t.Logf("PlanLookups: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if len(plan.Blocks) != 0 || len(plan.Checks) != 10 || plan.Cost() != 10 {
+		t.Errorf("PlanLookups: expected 10 checks rather than a check-block of the same cost, got %d blocks and %d checks", len(plan.Blocks), len(plan.Checks))
+	}
+
+	plan, _ = client.PlanLookups(ipAddresses)
+
+	if len(plan.Blocks) != 1 || len(plan.Checks) != 0 || plan.Cost() != 10 {
+		t.Errorf("PlanLookups: expected a check-block to save quota for 11 IPs, got %d blocks and %d checks", len(plan.Blocks), len(plan.Checks))
+	}
+
+	if _, err := client.PlanLookups(ipAddresses, BlockThreshold(-1)); err == nil {
+		t.Errorf("PlanLookups: expected err to be non-nil for a negative threshold")
+	}
+}
+
+func TestClient_Assess(t *testing.T) {
+	var checks, checkBlocks int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/check":
+			atomic.AddInt32(&checks, 1)
+			fmt.Fprintf(w, `{"data":{"ipAddress":"%s","abuseConfidenceScore":75,"totalReports":12,"countryCode":"US"}}`, r.URL.Query().Get("ipAddress"))
+		case "/check-block":
+			atomic.AddInt32(&checkBlocks, 1)
+			fmt.Fprint(w, `{"data":{"networkAddress":"1.1.1.0","reportedAddress":[{"ipAddress":"1.1.1.2","numReports":4,"abuseConfidenceScore":30,"countryCode":"AU"}]}}`)
+		}
+	})
+
+	assessments, err := client.Assess([]string{"8.8.8.8", "1.1.1.1", "1.1.1.2", "10.0.0.1"}, BlockThreshold(2))
+
+	if err != nil {
+		t.Logf("Assess: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if checks != 1 || checkBlocks != 1 {
+		t.Errorf("Assess: expected 1 check and 1 check-block request, got %d and %d", checks, checkBlocks)
+	}
+
+	if len(assessments) != 4 {
+		t.Logf("Assess: expected 4 assessments, got %d", len(assessments))
+		t.FailNow()
+	}
+
+	if a := assessments[0]; a.IPAddress != "8.8.8.8" || a.Source != SourceCheck || a.AbuseConfidenceScore != 75 || a.NumReports != 12 || a.Check == nil {
+		t.Errorf("Assess: expected full check for 8.8.8.8 with score 75, got %+v", a)
+	}
+
+	if a := assessments[1]; a.IPAddress != "1.1.1.1" || a.Source != SourceCheckBlock || a.AbuseConfidenceScore != 0 || a.Check != nil {
+		t.Errorf("Assess: expected unreported check-block result for 1.1.1.1, got %+v", a)
+	}
+
+	if a := assessments[2]; a.IPAddress != "1.1.1.2" || a.Source != SourceCheckBlock || a.AbuseConfidenceScore != 30 || a.NumReports != 4 {
+		t.Errorf("Assess: expected check-block result for 1.1.1.2 with score 30, got %+v", a)
+	}
+
+	if a := assessments[3]; a.IPAddress != "10.0.0.1" || a.Err == nil {
+		t.Errorf("Assess: expected error for 10.0.0.1, got %+v", a)
+	}
+}
+
+func TestCachedClient_Assess(t *testing.T) {
+	var checks, checkBlocks int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/check":
+			atomic.AddInt32(&checks, 1)
+			fmt.Fprintf(w, `{"data":{"ipAddress":"%s","abuseConfidenceScore":75,"totalReports":12,"countryCode":"US"}}`, r.URL.Query().Get("ipAddress"))
+		case "/check-block":
+			atomic.AddInt32(&checkBlocks, 1)
+			fmt.Fprint(w, `{"data":{"networkAddress":"1.1.1.0","reportedAddress":[]}}`)
+		}
+	})
+
+	cachedClient := NewCachedClient(client, NewMemoryCache(10))
+
+	for _, ipAddress := range []string{"1.1.1.1", "1.1.1.2"} {
+		if _, err := cachedClient.Check(ipAddress); err != nil {
+			t.Logf("Check: expected err to be nil, got %v", err)
+			t.FailNow()
+		}
+	}
+
+	ipAddresses := []string{"8.8.8.8", "1.1.1.1", "1.1.1.2", "1.1.1.3"}
+	plan, err := cachedClient.PlanLookups(ipAddresses, BlockThreshold(2))
+
+	if err != nil || len(plan.Blocks) != 1 {
+		t.Logf("PlanLookups: expected one block, got %v and %v", plan, err)
+		t.FailNow()
+	}
+
+	for i := 0; i < 2; i++ {
+		assessments, err := cachedClient.ExecutePlan(plan, BlockThreshold(2))
+
+		if err != nil || len(assessments) != 4 {
+			t.Logf("ExecutePlan: expected 4 assessments, got %d and %v", len(assessments), err)
+			t.FailNow()
+		}
+
+		for j, a := range assessments {
+			if a.IPAddress != ipAddresses[j] || a.Source != SourceCheck || a.AbuseConfidenceScore != 75 || a.Check == nil {
+				t.Errorf("ExecutePlan: expected full check for %s with score 75, got %+v", ipAddresses[j], a)
+			}
+		}
+	}
+
+	// 1.1.1.3 is left on its own in its block once the cached IPs are removed, so is checked individually.
+	if checks != 4 || checkBlocks != 0 {
+		t.Errorf("ExecutePlan: expected cached IPs not to be requested again, got %d checks and %d check-blocks", checks, checkBlocks)
+	}
+
+	if _, err := cachedClient.Assess(append(ipAddresses, "1.1.1.4", "1.1.1.5"), BlockThreshold(2)); err != nil {
+		t.Logf("Assess: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if checks != 4 || checkBlocks != 1 {
+		t.Errorf("Assess: expected 1 check-block for the uncached IPs, got %d checks and %d check-blocks", checks, checkBlocks)
+	}
+}
@@ -0,0 +1,287 @@
+package abuseipdb
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// WriteNginxGeo writes the blacklist to w as an nginx geo block, which sets a variable named after ExportName to the
+// abuse confidence score of the client address, or 0 if it isn't listed. Include the file in the http context, and
+// block listed clients with a directive such as "if ($abuseipdb) { return 403; }".
+// nginx can only validate the file as part of its whole configuration, so replace it with ReloadIncludeFile.
+func WriteNginxGeo(w io.Writer, response *BlacklistResponse, options ...ExportOption) error {
+	config := newExportConfig(options)
+	writer := newExportWriter(w)
+	ipv4, ipv6 := exportEntries(response, config)
+
+	writer.header("#", response, config)
+	writer.printf("geo $%s {\n", config.name)
+	writer.printf("\tdefault 0;\n")
+
+	for _, entry := range append(ipv4, ipv6...) {
+		writer.printf("\t%s %d;\n", entry.IPAddress, entry.AbuseConfidenceScore)
+	}
+
+	writer.printf("}\n")
+
+	return writer.flush()
+}
+
+// WriteNginxDeny writes the blacklist to w as nginx deny directives, to include in an http, server or location block.
+// nginx can only validate the file as part of its whole configuration, so replace it with ReloadIncludeFile.
+func WriteNginxDeny(w io.Writer, response *BlacklistResponse, options ...ExportOption) error {
+	config := newExportConfig(options)
+	writer := newExportWriter(w)
+	ipv4, ipv6 := exportEntries(response, config)
+
+	writer.header("#", response, config)
+
+	for _, entry := range append(ipv4, ipv6...) {
+		writer.printf("deny %s;\n", entry.IPAddress)
+	}
+
+	return writer.flush()
+}
+
+// WriteApacheRequire writes the blacklist to w as an Apache RequireAll block, which grants access to every client
+// except the listed addresses. Include the file in a Directory or Location section.
+// Apache can only validate the file as part of its whole configuration, so replace it with ReloadIncludeFile.
+func WriteApacheRequire(w io.Writer, response *BlacklistResponse, options ...ExportOption) error {
+	config := newExportConfig(options)
+	writer := newExportWriter(w)
+	ipv4, ipv6 := exportEntries(response, config)
+
+	writer.header("#", response, config)
+	writer.printf("<RequireAll>\n")
+	writer.printf("\tRequire all granted\n")
+
+	for _, entry := range append(ipv4, ipv6...) {
+		writer.printf("\tRequire not ip %s\n", entry.IPAddress)
+	}
+
+	writer.printf("</RequireAll>\n")
+
+	return writer.flush()
+}
+
+// WriteHAProxyACL writes the blacklist to w as an HAProxy ACL file, with one address per line.
+// Load it with a directive such as "acl abuseipdb src -f /etc/haproxy/abuseipdb.acl".
+// HAProxy can only validate the file as part of its whole configuration, so replace it with ReloadIncludeFile.
+func WriteHAProxyACL(w io.Writer, response *BlacklistResponse, options ...ExportOption) error {
+	config := newExportConfig(options)
+	writer := newExportWriter(w)
+	ipv4, ipv6 := exportEntries(response, config)
+
+	writer.header("#", response, config)
+
+	for _, entry := range append(ipv4, ipv6...) {
+		writer.printf("%s\n", entry.IPAddress)
+	}
+
+	return writer.flush()
+}
+
+// WriteHAProxyMap writes the blacklist to w as an HAProxy map file, mapping each address to its abuse confidence score.
+// Look scores up with a converter such as "src,map_ip(/etc/haproxy/abuseipdb.map,0)".
+// HAProxy can only validate the file as part of its whole configuration, so replace it with ReloadIncludeFile.
+func WriteHAProxyMap(w io.Writer, response *BlacklistResponse, options ...ExportOption) error {
+	config := newExportConfig(options)
+	writer := newExportWriter(w)
+	ipv4, ipv6 := exportEntries(response, config)
+
+	writer.header("#", response, config)
+
+	for _, entry := range append(ipv4, ipv6...) {
+		writer.printf("%s %d\n", entry.IPAddress, entry.AbuseConfidenceScore)
+	}
+
+	return writer.flush()
+}
+
+// CommandError represents a validate or reload command run by ReloadFile or ReloadIncludeFile which failed.
+type CommandError struct {
+	Command string
+	Output  string
+	Err     error
+}
+
+func (e CommandError) Error() string {
+	return fmt.Sprintf("abuseipdb: command %q failed: %v: %s", e.Command, e.Err, strings.TrimSpace(e.Output))
+}
+
+// ReloadFile replaces the file at path with the output of write, such as an export, and reloads the server using it.
+// The new file is written alongside the old one and the validate command is run before it is moved into place,
+// with each "{}" argument replaced by the path of the new file. This suits files which can be validated on their own,
+// such as an nftables script with "nft -c -f {}"; files included by a larger configuration, such as those for nginx,
+// Apache and HAProxy, must be replaced with ReloadIncludeFile instead. If validation fails, the new file is discarded,
+// the live file is left untouched and a CommandError is returned. Otherwise, the new file is moved into place and the
+// reload command is run. Either command may be empty to skip it. The previous file is kept with the ".bak" suffix.
+func ReloadFile(path string, write func(w io.Writer) error, validate []string, reload []string) error {
+	return reloadFile(path, write, validate, reload, false)
+}
+
+// ReloadIncludeFile replaces the file at path with the output of write in the same way as ReloadFile, for files
+// which can only be validated as part of the whole configuration which includes them, such as "nginx -t",
+// "apachectl configtest" or "haproxy -c -f /etc/haproxy/haproxy.cfg". The new file is moved into place first, then
+// the validate command is run, with each "{}" argument replaced by path, followed by the reload command.
+// If either command fails, the previous file is restored from the ".bak" copy, or the new file is removed if there
+// wasn't one, and the CommandError is returned. Either command may be empty to skip it.
+func ReloadIncludeFile(path string, write func(w io.Writer) error, validate []string, reload []string) error {
+	return reloadFile(path, write, validate, reload, true)
+}
+
+// reloadFile implements ReloadFile and ReloadIncludeFile, validating the new file in place if inPlace is true.
+func reloadFile(path string, write func(w io.Writer) error, validate []string, reload []string, inPlace bool) error {
+	temp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(temp.Name())
+
+	err = write(temp)
+
+	if err == nil {
+		err = temp.Sync()
+	}
+
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	info, err := os.Stat(path)
+
+	if err == nil {
+		mode = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	err = os.Chmod(temp.Name(), mode)
+
+	if err != nil {
+		return err
+	}
+
+	if !inPlace {
+		err = runCommand(validateArgs(validate, temp.Name()))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if info != nil {
+		err = backupFile(path, path+".bak")
+
+		if err != nil {
+			return err
+		}
+	}
+
+	err = os.Rename(temp.Name(), path)
+
+	if err != nil {
+		return err
+	}
+
+	if !inPlace {
+		return runCommand(reload)
+	}
+
+	err = runCommand(validateArgs(validate, path))
+
+	if err == nil {
+		err = runCommand(reload)
+	}
+
+	if err != nil {
+		if restoreErr := restoreFile(path, path+".bak", info != nil); restoreErr != nil {
+			return fmt.Errorf("%w (restoring the previous file also failed: %v)", err, restoreErr)
+		}
+	}
+
+	return err
+}
+
+// restoreFile puts the backup of the file at path back in place, keeping the backup. If there wasn't a previous file,
+// the file at path is removed instead.
+func restoreFile(path string, backup string, existed bool) error {
+	if !existed {
+		return os.Remove(path)
+	}
+
+	err := os.Rename(backup, path)
+
+	if err != nil {
+		return err
+	}
+
+	return backupFile(path, backup)
+}
+
+// backupFile links the file at path to backup, so the backup keeps the old contents once the file is replaced.
+// If hard links aren't supported, the file is copied instead.
+func backupFile(path string, backup string) error {
+	err := os.Remove(backup)
+
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if os.Link(path, backup) == nil {
+		return nil
+	}
+
+	contents, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(backup, contents, 0644)
+}
+
+// validateArgs returns the validate command with each "{}" argument replaced by the path of the file to validate.
+func validateArgs(command []string, path string) []string {
+	args := make([]string, len(command))
+
+	for i, arg := range command {
+		if arg == "{}" {
+			arg = path
+		}
+
+		args[i] = arg
+	}
+
+	return args
+}
+
+func runCommand(command []string) error {
+	if len(command) == 0 {
+		return nil
+	}
+
+	output, err := exec.Command(command[0], command[1:]...).CombinedOutput()
+
+	if err != nil {
+		return CommandError{
+			Command: strings.Join(command, " "),
+			Output:  string(output),
+			Err:     err,
+		}
+	}
+
+	return nil
+}
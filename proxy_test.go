@@ -0,0 +1,193 @@
+package abuseipdb
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteNginxGeo(t *testing.T) {
+	buffer := bytes.Buffer{}
+	err := WriteNginxGeo(&buffer, testExportBlacklist(), ExportMinScore(60))
+
+	if err != nil {
+		t.Logf("WriteNginxGeo: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	expected := `# AbuseIPDB blacklist generated at 2021-08-18T10:00:00Z
+# Minimum abuse confidence score: 60
+geo $abuseipdb {
+	default 0;
+	1.1.1.1 100;
+	2606:4700::1111 75;
+}
+`
+
+	if buffer.String() != expected {
+		t.Errorf("WriteNginxGeo: expected %q, got %q", expected, buffer.String())
+	}
+}
+
+func TestWriteProxyExports(t *testing.T) {
+	tests := map[string]struct {
+		write    func(io.Writer, *BlacklistResponse, ...ExportOption) error
+		expected string
+	}{
+		"WriteNginxDeny":     {WriteNginxDeny, "deny 1.1.1.1;\ndeny 2606:4700::1111;\n"},
+		"WriteApacheRequire": {WriteApacheRequire, "<RequireAll>\n\tRequire all granted\n\tRequire not ip 1.1.1.1\n\tRequire not ip 2606:4700::1111\n</RequireAll>\n"},
+		"WriteHAProxyACL":    {WriteHAProxyACL, "1.1.1.1\n2606:4700::1111\n"},
+		"WriteHAProxyMap":    {WriteHAProxyMap, "1.1.1.1 100\n2606:4700::1111 75\n"},
+	}
+
+	header := "# AbuseIPDB blacklist generated at 2021-08-18T10:00:00Z\n# Minimum abuse confidence score: 75\n"
+
+	for name, test := range tests {
+		buffer := bytes.Buffer{}
+		err := test.write(&buffer, testExportBlacklist(), ExportMinScore(75))
+
+		if err != nil {
+			t.Errorf("%s: expected err to be nil, got %v", name, err)
+
+			continue
+		}
+
+		if buffer.String() != header+test.expected {
+			t.Errorf("%s: expected %q, got %q", name, header+test.expected, buffer.String())
+		}
+	}
+}
+
+func TestReloadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "abuseipdb")
+
+	if err != nil {
+		t.Logf("ioutil.TempDir: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "abuseipdb.conf")
+	reloaded := filepath.Join(dir, "reloaded")
+	write := func(contents string) func(io.Writer) error {
+		return func(w io.Writer) error {
+			_, err := fmt.Fprint(w, contents)
+
+			return err
+		}
+	}
+
+	err = ReloadFile(path, write("first"), []string{"grep", "-q", "first", "{}"}, []string{"touch", reloaded})
+
+	if err != nil {
+		t.Logf("ReloadFile: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if contents, _ := ioutil.ReadFile(path); string(contents) != "first" {
+		t.Errorf("ReloadFile: expected file to contain %q, got %q", "first", contents)
+	}
+
+	if _, err := os.Stat(reloaded); err != nil {
+		t.Errorf("ReloadFile: expected reload command to be run, got %v", err)
+	}
+
+	validate := []string{"sh", "-c", `if grep -q second "$0"; then echo invalid config; exit 1; fi`, "{}"}
+	err = ReloadFile(path, write("second"), validate, []string{"false"})
+
+	if commandError, ok := err.(CommandError); !ok || commandError.Output != "invalid config\n" {
+		t.Errorf("ReloadFile: expected CommandError with the validate output, got %v", err)
+	}
+
+	if contents, _ := ioutil.ReadFile(path); string(contents) != "first" {
+		t.Errorf("ReloadFile: expected the live file to be left untouched, got %q", contents)
+	}
+
+	if matches, _ := filepath.Glob(path + ".tmp*"); len(matches) != 0 {
+		t.Errorf("ReloadFile: expected the rejected file to be removed, got %v", matches)
+	}
+
+	err = ReloadFile(path, write("third"), nil, nil)
+
+	if err != nil {
+		t.Logf("ReloadFile: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if contents, _ := ioutil.ReadFile(path + ".bak"); string(contents) != "first" {
+		t.Errorf("ReloadFile: expected the backup to contain %q, got %q", "first", contents)
+	}
+}
+
+func TestReloadIncludeFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "abuseipdb")
+
+	if err != nil {
+		t.Logf("ioutil.TempDir: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "abuseipdb.conf")
+	write := func(contents string) func(io.Writer) error {
+		return func(w io.Writer) error {
+			_, err := fmt.Fprint(w, contents)
+
+			return err
+		}
+	}
+
+	// The validate command stands in for a whole configuration check, which reads the live file.
+	validate := []string{"sh", "-c", `if grep -q invalid "$0"; then echo invalid config; exit 1; fi`, path}
+	err = ReloadIncludeFile(path, write("first"), validate, nil)
+
+	if err != nil {
+		t.Logf("ReloadIncludeFile: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	err = ReloadIncludeFile(path, write("invalid"), validate, []string{"touch", filepath.Join(dir, "reloaded")})
+
+	if commandError, ok := err.(CommandError); !ok || commandError.Output != "invalid config\n" {
+		t.Errorf("ReloadIncludeFile: expected CommandError with the validate output, got %v", err)
+	}
+
+	if contents, _ := ioutil.ReadFile(path); string(contents) != "first" {
+		t.Errorf("ReloadIncludeFile: expected the previous file to be restored after validation failed, got %q", contents)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "reloaded")); !os.IsNotExist(err) {
+		t.Errorf("ReloadIncludeFile: expected reload command not to be run after validation failed, got %v", err)
+	}
+
+	err = ReloadIncludeFile(path, write("second"), validate, []string{"false"})
+
+	if _, ok := err.(CommandError); !ok {
+		t.Errorf("ReloadIncludeFile: expected CommandError from the reload command, got %v", err)
+	}
+
+	if contents, _ := ioutil.ReadFile(path); string(contents) != "first" {
+		t.Errorf("ReloadIncludeFile: expected the previous file to be restored after reload failed, got %q", contents)
+	}
+
+	if contents, _ := ioutil.ReadFile(path + ".bak"); string(contents) != "first" {
+		t.Errorf("ReloadIncludeFile: expected the backup to be kept, got %q", contents)
+	}
+
+	newPath := filepath.Join(dir, "new.conf")
+	err = ReloadIncludeFile(newPath, write("invalid"), []string{"sh", "-c", `! grep -q invalid "$0"`, "{}"}, nil)
+
+	if _, ok := err.(CommandError); !ok {
+		t.Errorf("ReloadIncludeFile: expected CommandError with a new file, got %v", err)
+	}
+
+	if _, err := os.Stat(newPath); !os.IsNotExist(err) {
+		t.Errorf("ReloadIncludeFile: expected the new file to be removed when there was no previous file, got %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+package abuseipdb
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// minRefreshBackoff is how long a BlacklistRefresher waits before retrying after its first failed refresh.
+const minRefreshBackoff = time.Minute
+
+// BlacklistRefresher keeps an up-to-date BlacklistSet, refreshing it from the Blacklist endpoint on a schedule.
+// Each new set is swapped in atomically, so readers never see a partially loaded blacklist.
+// Use NewBlacklistRefresher to initialise a new refresher, and Run to start refreshing.
+type BlacklistRefresher struct {
+	client           *Client
+	blacklistOptions []BlacklistOption
+	interval         time.Duration
+	onError          func(error)
+	snapshotPath     string
+	current          atomic.Value
+
+	mu          sync.Mutex
+	subscribers []func(*BlacklistSet)
+	channels    []chan<- *BlacklistSet
+}
+
+type refresherConfig struct {
+	interval         time.Duration
+	blacklistOptions []BlacklistOption
+	onError          func(error)
+	snapshotPath     string
+}
+
+// RefresherOption sets an optional parameter when creating a BlacklistRefresher.
+type RefresherOption func(*refresherConfig)
+
+// RefreshInterval returns a RefresherOption that sets how often the blacklist is refreshed.
+// By default, the daily quota of the client's Plan is spread evenly across the day, with one request held in reserve.
+func RefreshInterval(interval time.Duration) RefresherOption {
+	return func(config *refresherConfig) {
+		config.interval = interval
+	}
+}
+
+// RefreshBlacklistOptions returns a RefresherOption that sets the options used for each call to Blacklist.
+func RefreshBlacklistOptions(options ...BlacklistOption) RefresherOption {
+	return func(config *refresherConfig) {
+		config.blacklistOptions = options
+	}
+}
+
+// RefreshErrors returns a RefresherOption that sets a callback which is called whenever a refresh fails.
+func RefreshErrors(callback func(error)) RefresherOption {
+	return func(config *refresherConfig) {
+		config.onError = callback
+	}
+}
+
+// RefreshSnapshot returns a RefresherOption that saves each new blacklist to a snapshot file at path,
+// and makes Run start from that snapshot, so the blacklist survives restarts without spending quota.
+// If the snapshot is younger than the refresh interval, Run waits until it is due before fetching a new one.
+func RefreshSnapshot(path string) RefresherOption {
+	return func(config *refresherConfig) {
+		config.snapshotPath = path
+	}
+}
+
+// NewBlacklistRefresher initialises a new refresher which fetches the blacklist using the client provided.
+func NewBlacklistRefresher(client *Client, options ...RefresherOption) *BlacklistRefresher {
+	config := refresherConfig{
+		interval: defaultRefreshInterval(client.Plan),
+		onError:  func(error) {},
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	refresher := BlacklistRefresher{
+		client:           client,
+		blacklistOptions: config.blacklistOptions,
+		interval:         config.interval,
+		onError:          config.onError,
+		snapshotPath:     config.snapshotPath,
+	}
+
+	return &refresher
+}
+
+// defaultRefreshInterval spreads a plan's daily blacklist quota across the day,
+// keeping one request in reserve for restarts.
+func defaultRefreshInterval(plan Plan) time.Duration {
+	requests := plan.BlacklistRequestsPerDay() - 1
+
+	if requests < 1 {
+		requests = 1
+	}
+
+	return 24 * time.Hour / time.Duration(requests)
+}
+
+// Current returns the blacklist currently in use, or nil if it hasn't been fetched yet.
+// The returned set must not be modified.
+func (r *BlacklistRefresher) Current() *BlacklistSet {
+	set, _ := r.current.Load().(*BlacklistSet)
+
+	return set
+}
+
+// GeneratedAt returns the time the blacklist currently in use was generated,
+// or the zero time if it hasn't been fetched yet.
+func (r *BlacklistRefresher) GeneratedAt() time.Time {
+	set := r.Current()
+
+	if set == nil {
+		return time.Time{}
+	}
+
+	return set.GeneratedAt()
+}
+
+// Subscribe registers a callback which is called with each new blacklist once it has been swapped in.
+// Callbacks are called one at a time, from the goroutine which refreshed the blacklist.
+func (r *BlacklistRefresher) Subscribe(callback func(*BlacklistSet)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscribers = append(r.subscribers, callback)
+}
+
+// Notify registers a channel which is sent each new blacklist once it has been swapped in.
+// Sends don't block, so if the channel isn't ready the blacklist is skipped; a buffer of 1 is enough
+// to always be able to receive the latest blacklist.
+func (r *BlacklistRefresher) Notify(channel chan<- *BlacklistSet) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.channels = append(r.channels, channel)
+}
+
+// Refresh fetches the blacklist immediately, and swaps it in if successful.
+// If a snapshot path is set, the new blacklist is saved to it; failures to save are passed to the error callback.
+func (r *BlacklistRefresher) Refresh() error {
+	blacklistResponse, err := r.client.Blacklist(r.blacklistOptions...)
+
+	if err != nil {
+		return err
+	}
+
+	set := NewBlacklistSet(blacklistResponse)
+	r.swap(set)
+
+	if r.snapshotPath != "" {
+		err = SaveBlacklistSet(r.snapshotPath, set)
+
+		if err != nil {
+			r.onError(err)
+		}
+	}
+
+	return nil
+}
+
+// restore swaps in the blacklist saved at the snapshot path, if there is one,
+// and returns how long to wait before it is due to be refreshed.
+func (r *BlacklistRefresher) restore() time.Duration {
+	if r.snapshotPath == "" || r.Current() != nil {
+		return 0
+	}
+
+	set, err := LoadBlacklistSet(r.snapshotPath)
+
+	if err != nil {
+		if !os.IsNotExist(err) {
+			r.onError(err)
+		}
+
+		return 0
+	}
+
+	r.swap(set)
+
+	return r.interval - time.Since(set.GeneratedAt())
+}
+
+func (r *BlacklistRefresher) swap(set *BlacklistSet) {
+	r.current.Store(set)
+
+	r.mu.Lock()
+	subscribers := r.subscribers
+	channels := r.channels
+	r.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(set)
+	}
+
+	for _, channel := range channels {
+		select {
+		case channel <- set:
+		default:
+		}
+	}
+}
+
+// Run refreshes the blacklist immediately, and then on the refresh interval until the context is cancelled.
+// If a snapshot path is set, the saved blacklist is swapped in first, and isn't refreshed until it is due.
+// Failed refreshes are retried with an exponential backoff, starting at one minute and never exceeding the refresh
+// interval, unless the API asks the client to wait for longer. The blacklist in use is kept until a refresh succeeds.
+func (r *BlacklistRefresher) Run(ctx context.Context) error {
+	if r.interval <= 0 {
+		return errors.New("interval must be greater than 0")
+	}
+
+	var backoff time.Duration
+
+	wait := r.restore()
+
+	for {
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		wait = r.interval
+		err := r.Refresh()
+
+		if err != nil {
+			r.onError(err)
+
+			backoff = nextBackoff(backoff, r.interval)
+			wait = backoff
+
+			if requestError, ok := err.(RequestError); ok && requestError.RetryAfter > wait {
+				wait = requestError.RetryAfter
+			}
+		} else {
+			backoff = 0
+		}
+	}
+}
+
+// nextBackoff doubles the previous backoff, starting at minRefreshBackoff and capped at max.
+func nextBackoff(previous time.Duration, max time.Duration) time.Duration {
+	next := previous * 2
+
+	if next < minRefreshBackoff {
+		next = minRefreshBackoff
+	}
+
+	if next > max {
+		next = max
+	}
+
+	return next
+}
@@ -0,0 +1,180 @@
+package abuseipdb
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBlacklistRefresher(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+
+		if n == 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		fmt.Fprintf(w, `{"meta":{"generatedAt":"2021-08-18T1%d:00:00+00:00"},"data":[{"ipAddress":"1.1.1.%d","abuseConfidenceScore":100}]}`, n, n)
+	})
+
+	var errs int32
+
+	refresher := NewBlacklistRefresher(client, RefreshBlacklistOptions(Limit(100)), RefreshErrors(func(error) {
+		atomic.AddInt32(&errs, 1)
+	}))
+
+	if refresher.Current() != nil || !refresher.GeneratedAt().IsZero() {
+		t.Errorf("BlacklistRefresher.Current: expected nil before the first refresh")
+	}
+
+	var subscribed int32
+
+	refresher.Subscribe(func(set *BlacklistSet) {
+		atomic.AddInt32(&subscribed, 1)
+	})
+
+	channel := make(chan *BlacklistSet, 1)
+	refresher.Notify(channel)
+
+	err := refresher.Refresh()
+
+	if err != nil {
+		t.Logf("BlacklistRefresher.Refresh: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	set := <-channel
+
+	if set != refresher.Current() || !set.Contains(net.ParseIP("1.1.1.1")) {
+		t.Errorf("BlacklistRefresher.Notify: expected current set containing 1.1.1.1")
+	}
+
+	if !refresher.GeneratedAt().Equal(time.Date(2021, 8, 18, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("BlacklistRefresher.GeneratedAt: expected 2021-08-18 11:00:00, got %v", refresher.GeneratedAt())
+	}
+
+	// A failed refresh keeps the current set.
+	err = refresher.Refresh()
+
+	if err == nil || refresher.Current() != set {
+		t.Errorf("BlacklistRefresher.Refresh: expected error and the current set to be kept, got %v", err)
+	}
+
+	refresher.interval = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		for atomic.LoadInt32(&requests) < 3 {
+			time.Sleep(time.Millisecond)
+		}
+
+		cancel()
+	}()
+
+	err = refresher.Run(ctx)
+
+	if err != context.Canceled {
+		t.Errorf("BlacklistRefresher.Run: expected context.Canceled, got %v", err)
+	}
+
+	if !refresher.Current().Contains(net.ParseIP("1.1.1.3")) {
+		t.Errorf("BlacklistRefresher.Run: expected refreshed set containing 1.1.1.3")
+	}
+
+	if atomic.LoadInt32(&subscribed) < 2 {
+		t.Errorf("BlacklistRefresher.Subscribe: expected callback for each new set, got %d", subscribed)
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	if got := nextBackoff(0, time.Hour); got != time.Minute {
+		t.Errorf("nextBackoff: expected 1m0s, got %s", got)
+	}
+
+	if got := nextBackoff(time.Minute, time.Hour); got != 2*time.Minute {
+		t.Errorf("nextBackoff: expected 2m0s, got %s", got)
+	}
+
+	if got := nextBackoff(40*time.Minute, time.Hour); got != time.Hour {
+		t.Errorf("nextBackoff: expected 1h0m0s, got %s", got)
+	}
+}
+
+func TestDefaultRefreshInterval(t *testing.T) {
+	if got := defaultRefreshInterval(PlanFree); got != 6*time.Hour {
+		t.Errorf("defaultRefreshInterval: expected 6h0m0s, got %s", got)
+	}
+}
+
+func TestBlacklistRefresher_Snapshot(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, `{"meta":{"generatedAt":"2021-08-18T10:00:00+00:00"},"data":[{"ipAddress":"1.1.1.1","abuseConfidenceScore":100}]}`)
+	})
+
+	dir, err := ioutil.TempDir("", "abuseipdb")
+
+	if err != nil {
+		t.Logf("ioutil.TempDir: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "blacklist.snapshot")
+	response := BlacklistResponse{}
+	response.Meta.GeneratedAt = time.Now()
+	response.Data = []BlacklistEntry{{IPAddress: "8.8.8.8", AbuseConfidenceScore: 50}}
+
+	err = SaveBlacklistSet(path, NewBlacklistSet(&response))
+
+	if err != nil {
+		t.Logf("SaveBlacklistSet: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	refresher := NewBlacklistRefresher(client, RefreshInterval(time.Hour), RefreshSnapshot(path))
+	channel := make(chan *BlacklistSet, 1)
+	refresher.Notify(channel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() {
+		done <- refresher.Run(ctx)
+	}()
+
+	set := <-channel
+	cancel()
+	<-done
+
+	if !set.Contains(net.ParseIP("8.8.8.8")) || atomic.LoadInt32(&requests) != 0 {
+		t.Errorf("BlacklistRefresher.Run: expected a fresh snapshot to be used without a request, got %d requests", requests)
+	}
+
+	err = refresher.Refresh()
+
+	if err != nil {
+		t.Logf("BlacklistRefresher.Refresh: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	saved, err := LoadBlacklistSet(path)
+
+	if err != nil || !saved.Contains(net.ParseIP("1.1.1.1")) {
+		t.Errorf("BlacklistRefresher.Refresh: expected the new blacklist to be saved, got %v", err)
+	}
+}
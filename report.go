@@ -3,7 +3,6 @@ package abuseipdb
 import (
 	"bytes"
 	"encoding/json"
-	"io"
 	"io/ioutil"
 	"mime/multipart"
 	"net/url"
@@ -45,6 +44,7 @@ type ReportOption func(*reportConfig)
 // Comment returns a ReportOption that sets the comment for a report.
 // This field should be used for any additional information to be included with the report,
 // including server logs, timestamps, packet samples, etc.
+// Comments longer than MaxCommentLength are truncated at the last line boundary that fits.
 func Comment(content string) ReportOption {
 	return func(config *reportConfig) {
 		config.comment = content
@@ -52,6 +52,7 @@ func Comment(content string) ReportOption {
 }
 
 // Report will submit a report for the IP provided.
+// Unless Client.SkipAddressValidation is set, an InvalidAddressError is returned for addresses which aren't public.
 func (c *Client) Report(ip string, categories []Category, options ...ReportOption) (*ReportResponse, error) {
 	config := defaultReportConfig
 
@@ -59,15 +60,41 @@ func (c *Client) Report(ip string, categories []Category, options ...ReportOptio
 		option(&config)
 	}
 
+	ip, err := c.normalizeIP(ip)
+
+	if err != nil {
+		return nil, err
+	}
+
 	values := url.Values{
 		"ip":         {ip},
 		"categories": {buildCategoryString(categories)},
 	}
 
+	if config.comment != "" && c.Sanitizer != nil {
+		config.comment = c.Sanitizer.Sanitize(config.comment)
+	}
+
+	config.comment = truncateComment(config.comment, MaxCommentLength)
+
 	if config.comment != "" {
 		values.Set("comment", config.comment)
 	}
 
+	reportResponse, err := c.report(values)
+
+	c.emit(Event{
+		Type:       EventReport,
+		IPAddress:  ip,
+		Report:     reportResponse,
+		Categories: categories,
+		Err:        err,
+	})
+
+	return reportResponse, err
+}
+
+func (c *Client) report(values url.Values) (*ReportResponse, error) {
 	res, err := c.makeRequest("POST", "/report", RequestOptions{
 		Headers: map[string]string{
 			"Content-Type": "application/x-www-form-urlencoded",
@@ -97,6 +124,8 @@ func (c *Client) Report(ip string, categories []Category, options ...ReportOptio
 }
 
 // BulkReport takes a CSV file containing multiple IPs to report in one go.
+// If the client has a Sanitizer, it is applied to the Comment column of the file before it is uploaded.
+// Otherwise, comments longer than MaxCommentLength are truncated at the last line boundary that fits.
 func (c *Client) BulkReport(filePath string) (*BulkReportResponse, error) {
 	file, err := os.Open(filePath)
 
@@ -115,7 +144,11 @@ func (c *Client) BulkReport(filePath string) (*BulkReportResponse, error) {
 		return nil, err
 	}
 
-	_, err = io.Copy(filePart, file)
+	if c.Sanitizer != nil {
+		err = c.Sanitizer.sanitizeCSV(file, filePart)
+	} else {
+		err = truncateCSV(file, filePart)
+	}
 
 	if err != nil {
 		return nil, err
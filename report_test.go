@@ -1,7 +1,12 @@
 package abuseipdb
 
 import (
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -18,6 +23,72 @@ func TestComment(t *testing.T) {
 	}
 }
 
+func TestClient_ReportCommentLength(t *testing.T) {
+	var comment string
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		comment = r.PostForm.Get("comment")
+
+		fmt.Fprint(w, `{"data":{"ipAddress":"1.1.1.1","abuseConfidenceScore":85}}`)
+	})
+
+	first := strings.Repeat("a", MaxCommentLength-10)
+	_, err := client.Report("1.1.1.1", []Category{CategorySSH}, Comment(first+"\n"+strings.Repeat("b", 20)))
+
+	if err != nil {
+		t.Logf("Report: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if comment != first {
+		t.Errorf("Report: expected the comment to be truncated to its first line without a Sanitizer, got %d characters", len(comment))
+	}
+}
+
+func TestClient_BulkReportCommentLength(t *testing.T) {
+	var records [][]string
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("csv")
+
+		if err == nil {
+			records, _ = csv.NewReader(file).ReadAll()
+			_ = file.Close()
+		}
+
+		fmt.Fprint(w, `{"data":{"savedReports":1,"invalidReports":[]}}`)
+	})
+
+	first := strings.Repeat("a", MaxCommentLength-10)
+	file, err := ioutil.TempFile(t.TempDir(), "bulk-*.csv")
+
+	if err != nil {
+		t.Logf("ioutil.TempFile: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	writer := csv.NewWriter(file)
+	_ = writer.Write([]string{"IP", "Categories", "ReportDate", "Comment"})
+	_ = writer.Write([]string{"1.1.1.1", "22", "2021-08-18T09:00:00Z", first + "\n" + strings.Repeat("b", 20)})
+	writer.Flush()
+	_ = file.Close()
+
+	if _, err := client.BulkReport(file.Name()); err != nil {
+		t.Logf("BulkReport: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if len(records) != 2 || len(records[1]) != 4 {
+		t.Logf("BulkReport: expected a header and 1 report to be uploaded, got %v", records)
+		t.FailNow()
+	}
+
+	if records[1][3] != first {
+		t.Errorf("BulkReport: expected the comment to be truncated to its first line without a Sanitizer, got %d characters", len(records[1][3]))
+	}
+}
+
 func TestClient_Report(t *testing.T) {
 	apiKey := os.Getenv("ABUSEIPDB_TOKEN")
 
@@ -27,6 +98,8 @@ func TestClient_Report(t *testing.T) {
 	}
 
 	client := NewClient(apiKey)
+	// Private addresses are used so that no real hosts are reported.
+	client.SkipAddressValidation = true
 
 	reportResponse, err := client.Report("172.16.0.5", []Category{CategoryDDoSAttack}, Comment("Test Request for https://gitlab.com/honour/abuseipdb"))
 
@@ -40,6 +113,20 @@ func TestClient_Report(t *testing.T) {
 	}
 }
 
+func TestClient_ReportInvalidAddress(t *testing.T) {
+	client := NewClient("")
+
+	_, err := client.Report("172.16.0.5", []Category{CategoryDDoSAttack})
+
+	if addressError, ok := err.(InvalidAddressError); ok {
+		if addressError.Reason != ReasonPrivate {
+			t.Errorf(`Report: expected reason to be "%s", got "%s"`, ReasonPrivate, addressError.Reason)
+		}
+	} else {
+		t.Errorf("Report: expected err to be of type InvalidAddressError, got %v", err)
+	}
+}
+
 func TestClient_BulkReport(t *testing.T) {
 	apiKey := os.Getenv("ABUSEIPDB_TOKEN")
 
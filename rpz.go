@@ -0,0 +1,115 @@
+package abuseipdb
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rpzTTL is the TTL of the records written by WriteRPZ, in seconds.
+const rpzTTL = 300
+
+// RPZAction is the action a resolver takes on answers matched by the rules written by WriteRPZ.
+type RPZAction int
+
+// A list of the RPZ actions supported by WriteRPZ.
+const (
+	// RPZNXDomain answers as though the name doesn't exist.
+	RPZNXDomain RPZAction = iota
+	// RPZNoData answers as though the name exists but has no records of the type queried.
+	RPZNoData
+)
+
+// WriteRPZ writes the blacklist to w as a DNS Response Policy Zone (RPZ) file, with an rpz-ip trigger for each listed
+// network, so resolvers such as BIND and Unbound rewrite answers which resolve to a listed address. Adjacent addresses
+// are collapsed into prefixes as AggregateBlacklist does. The action is set with RPZPolicy or RPZCNAME.
+// The serial of the zone is the time the blacklist was generated, or the current time if that isn't known, so it
+// changes with each refresh and secondaries transfer the new zone.
+func WriteRPZ(w io.Writer, response *BlacklistResponse, options ...ExportOption) error {
+	config := newExportConfig(options)
+	writer := newExportWriter(w)
+	ipv4, ipv6 := exportEntries(response, config)
+	generatedAt := time.Time{}
+
+	if response != nil {
+		generatedAt = response.Meta.GeneratedAt
+	}
+
+	action := "CNAME ."
+
+	switch {
+	case config.rpzCNAME != "":
+		action = "CNAME " + strings.TrimSuffix(config.rpzCNAME, ".") + "."
+	case config.rpzAction == RPZNoData:
+		action = "CNAME *."
+	}
+
+	writer.header(";", response, config)
+	writer.printf("$TTL %d\n", rpzTTL)
+	writer.printf("@ SOA localhost. hostmaster.localhost. %d 3600 600 86400 %d\n", zoneSerial(generatedAt), rpzTTL)
+	writer.printf("@ NS localhost.\n")
+
+	for _, prefix := range AggregateBlacklist(&BlacklistResponse{Data: append(ipv4, ipv6...)}) {
+		name := rpzName(prefix.Network)
+
+		if name != "" {
+			writer.printf("%s.rpz-ip %s\n", name, action)
+		}
+	}
+
+	return writer.flush()
+}
+
+// zoneSerial returns the SOA serial for a zone holding a blacklist generated at the time provided: the time in
+// seconds since the Unix epoch. If the time isn't known, the current time is used instead.
+func zoneSerial(generatedAt time.Time) uint32 {
+	if generatedAt.IsZero() {
+		generatedAt = time.Now()
+	}
+
+	return uint32(generatedAt.Unix())
+}
+
+// rpzName returns the rpz-ip owner name for a network in CIDR notation, without the rpz-ip label: the prefix length
+// followed by the address in reverse order. IPv6 addresses are written as reversed groups of hexadecimal digits,
+// with the longest run of zero groups written as "zz".
+func rpzName(network string) string {
+	_, ipNet, err := net.ParseCIDR(network)
+
+	if err != nil {
+		return ""
+	}
+
+	ones, _ := ipNet.Mask.Size()
+	labels := []string{strconv.Itoa(ones)}
+
+	if ip4 := ipNet.IP.To4(); ip4 != nil {
+		for i := net.IPv4len - 1; i >= 0; i-- {
+			labels = append(labels, strconv.Itoa(int(ip4[i])))
+		}
+
+		return strings.Join(labels, ".")
+	}
+
+	halves := strings.SplitN(ipNet.IP.String(), "::", 2)
+
+	for i := len(halves) - 1; i >= 0; i-- {
+		if i == 0 && len(halves) == 2 {
+			labels = append(labels, "zz")
+		}
+
+		if halves[i] == "" {
+			continue
+		}
+
+		groups := strings.Split(halves[i], ":")
+
+		for j := len(groups) - 1; j >= 0; j-- {
+			labels = append(labels, groups[j])
+		}
+	}
+
+	return strings.Join(labels, ".")
+}
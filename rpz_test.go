@@ -0,0 +1,88 @@
+package abuseipdb
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteRPZ(t *testing.T) {
+	reported := time.Date(2021, 8, 18, 9, 0, 0, 0, time.UTC)
+	response := testExportBlacklist()
+	response.Data = append(response.Data, BlacklistEntry{IPAddress: "1.1.1.0", AbuseConfidenceScore: 90, LastReportedAt: reported})
+
+	buffer := bytes.Buffer{}
+	err := WriteRPZ(&buffer, response, ExportMinScore(75))
+
+	if err != nil {
+		t.Logf("WriteRPZ: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	expected := `; AbuseIPDB blacklist generated at 2021-08-18T10:00:00Z
+; Minimum abuse confidence score: 75
+$TTL 300
+@ SOA localhost. hostmaster.localhost. 1629280800 3600 600 86400 300
+@ NS localhost.
+31.0.1.1.1.rpz-ip CNAME .
+128.1111.zz.4700.2606.rpz-ip CNAME .
+`
+
+	if buffer.String() != expected {
+		t.Errorf("WriteRPZ: expected %q, got %q", expected, buffer.String())
+	}
+
+	buffer.Reset()
+	_ = WriteRPZ(&buffer, testExportBlacklist(), RPZPolicy(RPZNoData))
+
+	if !strings.Contains(buffer.String(), "\n32.8.8.8.8.rpz-ip CNAME *.\n") {
+		t.Errorf("WriteRPZ: expected a NODATA rule for 8.8.8.8, got %q", buffer.String())
+	}
+
+	buffer.Reset()
+	_ = WriteRPZ(&buffer, testExportBlacklist(), RPZCNAME("blocked.example.com"))
+
+	if !strings.Contains(buffer.String(), "\n32.1.1.1.1.rpz-ip CNAME blocked.example.com.\n") {
+		t.Errorf("WriteRPZ: expected a CNAME rule for 1.1.1.1, got %q", buffer.String())
+	}
+}
+
+func TestWriteRPZ_Serial(t *testing.T) {
+	before := time.Now().Unix()
+	buffer := bytes.Buffer{}
+	err := WriteRPZ(&buffer, nil)
+
+	if err != nil {
+		t.Logf("WriteRPZ: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	var serial int64
+
+	for _, line := range strings.Split(buffer.String(), "\n") {
+		if strings.HasPrefix(line, "@ SOA ") {
+			serial, _ = strconv.ParseInt(strings.Fields(line)[4], 10, 64)
+		}
+	}
+
+	if serial < before || serial > time.Now().Unix() {
+		t.Errorf("WriteRPZ: expected the serial to be the current time without a generatedAt, got %d", serial)
+	}
+}
+
+func TestRPZName(t *testing.T) {
+	for network, expected := range map[string]string{
+		"192.0.2.0/24":             "24.0.2.0.192",
+		"2001:db8::/32":            "32.zz.db8.2001",
+		"2001:db8:0:0:1::1/128":    "128.1.0.0.1.zz.db8.2001",
+		"::/0":                     "0.zz",
+		"2001:db8:1:2:3:4:5:6/128": "128.6.5.4.3.2.1.db8.2001",
+		"invalid":                  "",
+	} {
+		if name := rpzName(network); name != expected {
+			t.Errorf("rpzName: expected %s for %s, got %s", expected, network, name)
+		}
+	}
+}
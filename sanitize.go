@@ -0,0 +1,324 @@
+package abuseipdb
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxCommentLength is the maximum number of characters the AbuseIPDB API accepts for a report comment.
+const MaxCommentLength = 1024
+
+const (
+	redactedIP       = "[redacted-ip]"
+	redactedHostname = "[redacted-host]"
+	redactedEmail    = "[redacted-email]"
+	redactedUsername = "[redacted-user]"
+	redactedToken    = "[redacted-token]"
+)
+
+var (
+	ipv4Pattern  = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	ipv6Pattern  = regexp.MustCompile(`[0-9A-Fa-f]*:[0-9A-Fa-f:]*:[0-9A-Fa-f:.]*`)
+	emailPattern = regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}\b`)
+
+	// usernamePatterns match the usernames found in common authentication log lines (sshd, PAM, etc.).
+	// The username is always the second capture group.
+	usernamePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)(\b(?:invalid|illegal) user )(\S+)()`),
+		regexp.MustCompile(`(?i)(\bfor )(\S+)( from )`),
+		regexp.MustCompile(`(?i)(\b(?:r?user|username|login|logname)=)(\S+)()`),
+	}
+
+	// tokenPatterns match bearer tokens, basic credentials and key/value pairs which look like secrets.
+	tokenPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)(\bbearer\s+)()[a-z0-9._~+/=-]+`),
+		regexp.MustCompile(`(?i)(\bbasic\s+)()[a-z0-9+/=]{8,}`),
+		regexp.MustCompile(`(?i)(\b(?:api[_-]?key|access[_-]?token|auth[_-]?token|token|secret|passw(?:or)?d|key)\s*[=:]\s*)("?)[^\s"&,;]+`),
+	}
+)
+
+// Sanitizer removes sensitive information from report comments before they are submitted to AbuseIPDB,
+// as requested by the AbuseIPDB reporting guidelines.
+// Use NewSanitizer to initialise a new sanitizer, and assign it to Client.Sanitizer to have it applied
+// to every call to Report and BulkReport.
+type Sanitizer struct {
+	networks  []*net.IPNet
+	hostnames []*regexp.Regexp
+	maxLength int
+}
+
+type sanitizerConfig struct {
+	networks  []string
+	hostnames []string
+	domains   []string
+	maxLength int
+}
+
+var defaultSanitizerConfig = sanitizerConfig{
+	maxLength: MaxCommentLength,
+}
+
+// SanitizerOption sets an optional parameter when creating a Sanitizer.
+type SanitizerOption func(*sanitizerConfig)
+
+// RedactNetworks returns a SanitizerOption that adds IP ranges which should be removed from comments.
+// Both CIDR notation and single IP addresses (either v4 or v6) are accepted.
+func RedactNetworks(networks ...string) SanitizerOption {
+	return func(config *sanitizerConfig) {
+		config.networks = append(config.networks, networks...)
+	}
+}
+
+// RedactHostnames returns a SanitizerOption that adds hostnames which should be removed from comments.
+// Hostnames are matched exactly, ignoring case.
+func RedactHostnames(hostnames ...string) SanitizerOption {
+	return func(config *sanitizerConfig) {
+		config.hostnames = append(config.hostnames, hostnames...)
+	}
+}
+
+// RedactDomains returns a SanitizerOption that adds domains which should be removed from comments.
+// The domain itself and any of its subdomains are matched, ignoring case.
+func RedactDomains(domains ...string) SanitizerOption {
+	return func(config *sanitizerConfig) {
+		config.domains = append(config.domains, domains...)
+	}
+}
+
+// CommentLength returns a SanitizerOption that sets the maximum length of a sanitized comment.
+// Comments longer than this are truncated at the last line boundary that fits.
+// The default value is MaxCommentLength, and can be any value between 1 and MaxCommentLength.
+func CommentLength(length int) SanitizerOption {
+	return func(config *sanitizerConfig) {
+		config.maxLength = length
+	}
+}
+
+// NewSanitizer initialises a new sanitizer for report comments.
+// Email addresses, usernames found in authentication logs, bearer tokens and API keys are always redacted.
+func NewSanitizer(options ...SanitizerOption) (*Sanitizer, error) {
+	config := defaultSanitizerConfig
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	if config.maxLength < 1 || config.maxLength > MaxCommentLength {
+		return nil, fmt.Errorf("maxLength must be between 1 and %d", MaxCommentLength)
+	}
+
+	sanitizer := Sanitizer{
+		maxLength: config.maxLength,
+	}
+
+	for _, network := range config.networks {
+		ipNet, err := parseNetwork(network)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sanitizer.networks = append(sanitizer.networks, ipNet)
+	}
+
+	for _, hostname := range config.hostnames {
+		hostname = strings.Trim(hostname, ".")
+
+		if hostname == "" {
+			return nil, errors.New("hostnames must not be empty")
+		}
+
+		sanitizer.hostnames = append(sanitizer.hostnames, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(hostname)+`\b`))
+	}
+
+	for _, domain := range config.domains {
+		domain = strings.Trim(domain, ".")
+
+		if domain == "" {
+			return nil, errors.New("domains must not be empty")
+		}
+
+		sanitizer.hostnames = append(sanitizer.hostnames, regexp.MustCompile(`(?i)\b(?:[a-z0-9-]+\.)*`+regexp.QuoteMeta(domain)+`\b`))
+	}
+
+	return &sanitizer, nil
+}
+
+// Sanitize returns a copy of the comment with sensitive information redacted,
+// truncated to the maximum comment length.
+func (s *Sanitizer) Sanitize(comment string) string {
+	// Email addresses are redacted before hostnames, otherwise the domain
+	// part would be redacted on its own and leave the local part behind.
+	comment = emailPattern.ReplaceAllString(comment, redactedEmail)
+
+	for _, pattern := range tokenPatterns {
+		comment = pattern.ReplaceAllString(comment, "${1}${2}"+redactedToken)
+	}
+
+	for _, pattern := range usernamePatterns {
+		comment = pattern.ReplaceAllString(comment, "${1}"+redactedUsername+"${3}")
+	}
+
+	if len(s.networks) > 0 {
+		comment = ipv6Pattern.ReplaceAllStringFunc(comment, s.redactIP)
+		comment = ipv4Pattern.ReplaceAllStringFunc(comment, s.redactIP)
+	}
+
+	for _, pattern := range s.hostnames {
+		comment = pattern.ReplaceAllString(comment, redactedHostname)
+	}
+
+	return truncateComment(comment, s.maxLength)
+}
+
+// redactIP redacts a candidate matched by the IP patterns if it is in one of the networks. The IPv6 pattern also
+// matches punctuation following the address, such as a full stop ending a sentence, so trailing full stops and
+// colons are dropped until the candidate parses, and kept after the redacted address.
+func (s *Sanitizer) redactIP(candidate string) string {
+	end := len(candidate)
+
+	for end > 0 {
+		if ip := net.ParseIP(candidate[:end]); ip != nil {
+			for _, network := range s.networks {
+				if network.Contains(ip) {
+					return redactedIP + candidate[end:]
+				}
+			}
+
+			return candidate
+		}
+
+		if last := candidate[end-1]; last != '.' && last != ':' {
+			break
+		}
+
+		end--
+	}
+
+	return candidate
+}
+
+// sanitizeCSV copies a bulk report CSV file from r to w, sanitizing the contents of the Comment column.
+func (s *Sanitizer) sanitizeCSV(r io.Reader, w io.Writer) error {
+	return rewriteCSVComments(r, w, s.Sanitize)
+}
+
+// truncateCSV copies a bulk report CSV file from r to w, truncating the contents of the Comment column to
+// MaxCommentLength.
+func truncateCSV(r io.Reader, w io.Writer) error {
+	return rewriteCSVComments(r, w, func(comment string) string {
+		return truncateComment(comment, MaxCommentLength)
+	})
+}
+
+// rewriteCSVComments copies a bulk report CSV file from r to w, passing the contents of the Comment column through
+// the rewrite function provided.
+func rewriteCSVComments(r io.Reader, w io.Writer, rewrite func(comment string) string) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	writer := csv.NewWriter(w)
+
+	header, err := reader.Read()
+
+	if err != nil {
+		return err
+	}
+
+	err = writer.Write(header)
+
+	if err != nil {
+		return err
+	}
+
+	commentColumn := -1
+
+	for i, column := range header {
+		if strings.EqualFold(strings.TrimSpace(column), "Comment") {
+			commentColumn = i
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if commentColumn != -1 && commentColumn < len(record) {
+			record[commentColumn] = rewrite(record[commentColumn])
+		}
+
+		err = writer.Write(record)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// parseNetwork parses either CIDR notation or a single IP address into a network.
+func parseNetwork(network string) (*net.IPNet, error) {
+	if strings.Contains(network, "/") {
+		_, ipNet, err := net.ParseCIDR(network)
+
+		return ipNet, err
+	}
+
+	ip := net.ParseIP(network)
+
+	if ip == nil {
+		return nil, &net.ParseError{Type: "IP address", Text: network}
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// truncateComment shortens a comment to at most maxLength characters.
+// Where possible the comment is cut at a line boundary, so that partial log lines aren't submitted.
+func truncateComment(comment string, maxLength int) string {
+	if utf8.RuneCountInString(comment) <= maxLength {
+		return comment
+	}
+
+	// Leading line breaks are dropped, as they would otherwise be the only line boundary that fits.
+	comment = strings.TrimLeft(comment, "\r\n")
+
+	if utf8.RuneCountInString(comment) <= maxLength {
+		return comment
+	}
+
+	end := 0
+
+	for i := 0; i < maxLength; i++ {
+		_, size := utf8.DecodeRuneInString(comment[end:])
+		end += size
+	}
+
+	truncated := comment[:end]
+
+	if i := strings.LastIndexByte(truncated, '\n'); i > 0 {
+		truncated = truncated[:i]
+	}
+
+	return strings.TrimRight(truncated, "\r\n")
+}
@@ -0,0 +1,162 @@
+package abuseipdb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The vendor and product recorded in CEF and LEEF headers.
+const (
+	siemVendor  = "AbuseIPDB"
+	siemProduct = "abuseipdb-go"
+)
+
+// The format of the devTime field of LEEF events, as a Go layout and as the Java pattern LEEF uses to describe it.
+const (
+	leefTimeFormat     = "2006-01-02T15:04:05.000-0700"
+	leefJavaTimeFormat = "yyyy-MM-dd'T'HH:mm:ss.SSSZ"
+)
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+	leefHeaderEscaper   = strings.NewReplacer(`|`, `\|`, "\r", " ", "\n", " ")
+	leefValueEscaper    = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+)
+
+// siemField is a key and value in the extension of a CEF or LEEF event.
+type siemField struct {
+	key   string
+	value string
+}
+
+// siemFields returns the fields describing an event, leaving out those which are empty.
+func siemFields(event Event) []siemField {
+	fields := []siemField{
+		{"src", event.IPAddress},
+		{"act", event.Type.String()},
+		{"outcome", "success"},
+		{"abuseConfidenceScore", strconv.Itoa(event.AbuseConfidenceScore())},
+	}
+
+	if event.Err != nil {
+		fields[2].value = "failure"
+		fields = append(fields, siemField{"reason", event.Err.Error()})
+	}
+
+	if event.Check != nil {
+		fields = append(fields,
+			siemField{"countryCode", event.Check.Data.CountryCode},
+			siemField{"isp", event.Check.Data.ISP},
+			siemField{"usageType", event.Check.Data.UsageType},
+			siemField{"totalReports", strconv.Itoa(event.Check.Data.TotalReports)},
+		)
+	}
+
+	var categories []string
+
+	for _, category := range event.ReportedCategories() {
+		categories = append(categories, category.String())
+	}
+
+	fields = append(fields, siemField{"categories", strings.Join(categories, ",")})
+
+	nonEmpty := fields[:0]
+
+	for _, field := range fields {
+		if field.value != "" {
+			nonEmpty = append(nonEmpty, field)
+		}
+	}
+
+	return nonEmpty
+}
+
+// cefKeys maps fields to the CEF dictionary, using custom string and number fields where there's no standard key.
+var cefKeys = map[string][2]string{
+	"abuseConfidenceScore": {"cn1", "cn1Label"},
+	"totalReports":         {"cn2", "cn2Label"},
+	"countryCode":          {"cs1", "cs1Label"},
+	"isp":                  {"cs2", "cs2Label"},
+	"usageType":            {"cs3", "cs3Label"},
+	"categories":           {"cs4", "cs4Label"},
+}
+
+// FormatCEF formats an event in ArcSight Common Event Format (CEF). The severity is derived from the abuse confidence
+// score, from 0 to 10. Fields without a standard CEF key are written as labelled custom fields, such as cn1 for the
+// abuse confidence score and cs4 for the categories.
+func FormatCEF(event Event) string {
+	builder := strings.Builder{}
+
+	fmt.Fprintf(&builder, "CEF:0|%s|%s|%s|%s|%s|%d|",
+		cefHeaderEscaper.Replace(siemVendor),
+		cefHeaderEscaper.Replace(siemProduct),
+		cefHeaderEscaper.Replace(version),
+		cefHeaderEscaper.Replace(event.Type.String()),
+		cefHeaderEscaper.Replace(siemName(event)),
+		event.AbuseConfidenceScore()/10,
+	)
+
+	fmt.Fprintf(&builder, "rt=%d", siemTime(event).UnixNano()/1e6)
+
+	for _, field := range siemFields(event) {
+		key := field.key
+
+		if custom, ok := cefKeys[key]; ok {
+			fmt.Fprintf(&builder, " %s=%s", custom[1], field.key)
+			key = custom[0]
+		}
+
+		if key == "reason" {
+			key = "msg"
+		}
+
+		fmt.Fprintf(&builder, " %s=%s", key, cefExtensionEscaper.Replace(field.value))
+	}
+
+	return builder.String()
+}
+
+// FormatLEEF formats an event in IBM QRadar Log Event Extended Format (LEEF) version 1.0, with tab-separated fields.
+// The severity is derived from the abuse confidence score, from 0 to 10.
+func FormatLEEF(event Event) string {
+	builder := strings.Builder{}
+
+	fmt.Fprintf(&builder, "LEEF:1.0|%s|%s|%s|%s|",
+		leefHeaderEscaper.Replace(siemVendor),
+		leefHeaderEscaper.Replace(siemProduct),
+		leefHeaderEscaper.Replace(version),
+		leefHeaderEscaper.Replace(event.Type.String()),
+	)
+
+	fmt.Fprintf(&builder, "devTime=%s\tdevTimeFormat=%s\tsev=%d\tcat=%s",
+		siemTime(event).UTC().Format(leefTimeFormat), leefJavaTimeFormat, event.AbuseConfidenceScore()/10, event.Type.String())
+
+	for _, field := range siemFields(event) {
+		fmt.Fprintf(&builder, "\t%s=%s", field.key, leefValueEscaper.Replace(field.value))
+	}
+
+	return builder.String()
+}
+
+// siemTime returns the time of an event, or now for events created without one.
+func siemTime(event Event) time.Time {
+	if event.Time.IsZero() {
+		return time.Now()
+	}
+
+	return event.Time
+}
+
+func siemName(event Event) string {
+	switch event.Type {
+	case EventCheck:
+		return "IP address checked"
+	case EventReport:
+		return "IP address reported"
+	default:
+		return "AbuseIPDB event"
+	}
+}
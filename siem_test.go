@@ -0,0 +1,55 @@
+package abuseipdb
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func testSIEMEvent() Event {
+	check := CheckResponse{}
+	check.Data.IPAddress = "1.1.1.1"
+	check.Data.AbuseConfidenceScore = 87
+	check.Data.CountryCode = "AU"
+	check.Data.ISP = "APNIC=Research|Labs"
+	check.Data.UsageType = "Content Delivery Network"
+	check.Data.TotalReports = 12
+	check.Data.Reports = []Report{{Categories: []int{int(CategorySSH)}}}
+
+	return Event{
+		Type:      EventCheck,
+		Time:      time.Date(2021, 8, 18, 10, 0, 0, 0, time.UTC),
+		IPAddress: "1.1.1.1",
+		Check:     &check,
+	}
+}
+
+func TestFormatCEF(t *testing.T) {
+	expected := "CEF:0|AbuseIPDB|abuseipdb-go|" + version + "|check|IP address checked|8|rt=1629280800000" +
+		" src=1.1.1.1 act=check outcome=success cn1Label=abuseConfidenceScore cn1=87" +
+		` cs1Label=countryCode cs1=AU cs2Label=isp cs2=APNIC\=Research|Labs cs3Label=usageType cs3=Content Delivery Network` +
+		" cn2Label=totalReports cn2=12 cs4Label=categories cs4=SSH"
+
+	if formatted := FormatCEF(testSIEMEvent()); formatted != expected {
+		t.Errorf("FormatCEF: expected %q, got %q", expected, formatted)
+	}
+
+	event := Event{Type: EventReport, Time: time.Unix(1629280800, 0), IPAddress: "1.1.1.1", Categories: []Category{CategoryPortScan}, Err: errors.New("rate\nlimited")}
+	expected = "CEF:0|AbuseIPDB|abuseipdb-go|" + version + "|report|IP address reported|0|rt=1629280800000" +
+		` src=1.1.1.1 act=report outcome=failure cn1Label=abuseConfidenceScore cn1=0 msg=rate\nlimited cs4Label=categories cs4=PortScan`
+
+	if formatted := FormatCEF(event); formatted != expected {
+		t.Errorf("FormatCEF: expected %q, got %q", expected, formatted)
+	}
+}
+
+func TestFormatLEEF(t *testing.T) {
+	expected := "LEEF:1.0|AbuseIPDB|abuseipdb-go|" + version + "|check|" +
+		"devTime=2021-08-18T10:00:00.000+0000\tdevTimeFormat=yyyy-MM-dd'T'HH:mm:ss.SSSZ\tsev=8\tcat=check" +
+		"\tsrc=1.1.1.1\tact=check\toutcome=success\tabuseConfidenceScore=87\tcountryCode=AU\tisp=APNIC=Research|Labs" +
+		"\tusageType=Content Delivery Network\ttotalReports=12\tcategories=SSH"
+
+	if formatted := FormatLEEF(testSIEMEvent()); formatted != expected {
+		t.Errorf("FormatLEEF: expected %q, got %q", expected, formatted)
+	}
+}
@@ -0,0 +1,333 @@
+package abuseipdb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// snapshotVersion is the version of the snapshot format written by SaveBlacklistSet.
+const snapshotVersion = 1
+
+// snapshotMagic identifies a blacklist snapshot file.
+var snapshotMagic = [4]byte{'A', 'B', 'L', 'S'}
+
+// ErrInvalidSnapshot is returned when a blacklist snapshot is truncated, corrupt or uses an unsupported version.
+var ErrInvalidSnapshot = errors.New("abuseipdb: invalid blacklist snapshot")
+
+// snapshotHeader is the fixed-size header at the start of a snapshot, followed by the IPv4 addresses, the IPv6
+// addresses and the networks, and then a CRC-32 (IEEE) checksum of everything before it.
+// All integers are big-endian, and times are stored as seconds since the epoch, with zero meaning unset.
+//
+// Each IPv4 address is stored as 4 address bytes, a score byte and 4 bytes for the time of the last report.
+// Each IPv6 address is stored the same way, with 16 address bytes.
+// Each network is stored as an address length byte (4 or 16), a prefix length byte, the address bytes and a score byte.
+type snapshotHeader struct {
+	Magic       [4]byte
+	Version     uint16
+	_           uint16
+	GeneratedAt int64
+	IPv4        uint32
+	IPv6        uint32
+	Networks    uint32
+}
+
+// WriteSnapshot writes the set to w in the compact binary format read by ReadBlacklistSnapshot.
+// Entries are written in address order, so the same set always produces the same snapshot.
+func (s *BlacklistSet) WriteSnapshot(w io.Writer) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	checksum := crc32.NewIEEE()
+	writer := bufio.NewWriter(io.MultiWriter(w, checksum))
+
+	header := snapshotHeader{
+		Magic:    snapshotMagic,
+		Version:  snapshotVersion,
+		IPv4:     uint32(len(s.ipv4)),
+		IPv6:     uint32(len(s.ipv6)),
+		Networks: uint32(s.networks),
+	}
+
+	if !s.generatedAt.IsZero() {
+		header.GeneratedAt = s.generatedAt.Unix()
+	}
+
+	err := binary.Write(writer, binary.BigEndian, header)
+
+	if err != nil {
+		return err
+	}
+
+	ipv4 := make([]uint32, 0, len(s.ipv4))
+
+	for key := range s.ipv4 {
+		ipv4 = append(ipv4, key)
+	}
+
+	sort.Slice(ipv4, func(i, j int) bool { return ipv4[i] < ipv4[j] })
+
+	record := make([]byte, 0, 2+net.IPv6len+1+4)
+
+	for _, key := range ipv4 {
+		record = appendUint32(record[:0], key)
+		record = appendSnapshotValue(record, s.ipv4[key])
+
+		_, err = writer.Write(record)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	ipv6 := make([][16]byte, 0, len(s.ipv6))
+
+	for key := range s.ipv6 {
+		ipv6 = append(ipv6, key)
+	}
+
+	sort.Slice(ipv6, func(i, j int) bool { return bytes.Compare(ipv6[i][:], ipv6[j][:]) < 0 })
+
+	for _, key := range ipv6 {
+		record = append(record[:0], key[:]...)
+		record = appendSnapshotValue(record, s.ipv6[key])
+
+		_, err = writer.Write(record)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, root := range []*prefixNode{s.networks4, s.networks6} {
+		length := net.IPv4len
+
+		if root == s.networks6 {
+			length = net.IPv6len
+		}
+
+		walkNetworks(root, make(net.IP, length), 0, func(ip net.IP, prefix int, value blacklistValue) {
+			if err != nil {
+				return
+			}
+
+			record = append(record[:0], byte(len(ip)), byte(prefix))
+			record = append(record, ip...)
+			record = append(record, value.score)
+
+			_, err = writer.Write(record)
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writer.Flush()
+
+	if err != nil {
+		return err
+	}
+
+	return binary.Write(w, binary.BigEndian, checksum.Sum32())
+}
+
+func appendSnapshotValue(record []byte, value blacklistValue) []byte {
+	record = append(record, value.score)
+
+	return appendUint32(record, value.lastReportedAt)
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// walkNetworks calls fn for each network in the prefix trie below node, in address order.
+// The ip is reused between calls, so must be copied if it is retained.
+func walkNetworks(node *prefixNode, ip net.IP, depth int, fn func(ip net.IP, prefix int, value blacklistValue)) {
+	if node.value != nil {
+		fn(ip, node.prefix, *node.value)
+	}
+
+	for bit, child := range node.children {
+		if child == nil {
+			continue
+		}
+
+		mask := byte(1) << (7 - uint(depth%8))
+
+		if bit == 1 {
+			ip[depth/8] |= mask
+		}
+
+		walkNetworks(child, ip, depth+1, fn)
+
+		ip[depth/8] &^= mask
+	}
+}
+
+// ReadBlacklistSnapshot initialises a new set from a snapshot written by WriteSnapshot.
+// ErrInvalidSnapshot is returned if the snapshot is truncated, its checksum doesn't match or its version isn't supported.
+func ReadBlacklistSnapshot(r io.Reader) (*BlacklistSet, error) {
+	reader := bufio.NewReader(r)
+	checksum := crc32.NewIEEE()
+	body := io.TeeReader(reader, checksum)
+	header := snapshotHeader{}
+
+	err := binary.Read(body, binary.BigEndian, &header)
+
+	if err != nil {
+		return nil, snapshotError(err)
+	}
+
+	if header.Magic != snapshotMagic {
+		return nil, fmt.Errorf("%w: not a snapshot file", ErrInvalidSnapshot)
+	}
+
+	if header.Version != snapshotVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, header.Version)
+	}
+
+	set := NewBlacklistSet(nil)
+
+	if header.GeneratedAt != 0 {
+		set.generatedAt = time.Unix(header.GeneratedAt, 0).UTC()
+	}
+
+	record := make([]byte, 2+net.IPv6len+1+4)
+
+	for i := uint32(0); i < header.IPv4; i++ {
+		_, err = io.ReadFull(body, record[:net.IPv4len+5])
+
+		if err != nil {
+			return nil, snapshotError(err)
+		}
+
+		set.ipv4[binary.BigEndian.Uint32(record)] = readSnapshotValue(record[net.IPv4len:])
+	}
+
+	for i := uint32(0); i < header.IPv6; i++ {
+		_, err = io.ReadFull(body, record[:net.IPv6len+5])
+
+		if err != nil {
+			return nil, snapshotError(err)
+		}
+
+		key := [16]byte{}
+		copy(key[:], record)
+		set.ipv6[key] = readSnapshotValue(record[net.IPv6len:])
+	}
+
+	for i := uint32(0); i < header.Networks; i++ {
+		_, err = io.ReadFull(body, record[:2])
+
+		if err != nil {
+			return nil, snapshotError(err)
+		}
+
+		length, prefix := int(record[0]), int(record[1])
+
+		if (length != net.IPv4len && length != net.IPv6len) || prefix > length*8 {
+			return nil, fmt.Errorf("%w: invalid network", ErrInvalidSnapshot)
+		}
+
+		_, err = io.ReadFull(body, record[:length+1])
+
+		if err != nil {
+			return nil, snapshotError(err)
+		}
+
+		ip := make(net.IP, length)
+		copy(ip, record)
+		set.insertNetwork(ip, prefix, blacklistValue{score: record[length]})
+	}
+
+	expected := checksum.Sum32()
+	var actual uint32
+
+	err = binary.Read(reader, binary.BigEndian, &actual)
+
+	if err != nil {
+		return nil, snapshotError(err)
+	}
+
+	if actual != expected {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidSnapshot)
+	}
+
+	return set, nil
+}
+
+func readSnapshotValue(record []byte) blacklistValue {
+	return blacklistValue{
+		score:          record[0],
+		lastReportedAt: binary.BigEndian.Uint32(record[1:]),
+	}
+}
+
+func snapshotError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: truncated", ErrInvalidSnapshot)
+	}
+
+	return err
+}
+
+// SaveBlacklistSet writes a snapshot of the set to the file at path.
+// The snapshot is written to a temporary file in the same directory, which then replaces the file at path,
+// so a crash while saving never leaves a partially written snapshot behind.
+// The permissions of an existing snapshot are kept, and new snapshots are readable by every user (0644).
+func SaveBlacklistSet(path string, set *BlacklistSet) error {
+	temp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(temp.Name())
+
+	err = set.WriteSnapshot(temp)
+
+	if err == nil {
+		err = temp.Sync()
+	}
+
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = chmodLike(temp.Name(), path)
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), path)
+}
+
+// LoadBlacklistSet reads a snapshot saved by SaveBlacklistSet from the file at path.
+func LoadBlacklistSet(path string) (*BlacklistSet, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return ReadBlacklistSnapshot(file)
+}
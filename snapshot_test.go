@@ -0,0 +1,150 @@
+package abuseipdb
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBlacklistSet_WriteSnapshot(t *testing.T) {
+	set, err := ReadBlacklistSet(strings.NewReader(testBlacklistJSON))
+
+	if err != nil {
+		t.Logf("ReadBlacklistSet: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	_ = set.AddNetwork("192.0.2.0/24", 80)
+	_ = set.AddNetwork("2001:db8::/32", 60)
+
+	buffer := bytes.Buffer{}
+	err = set.WriteSnapshot(&buffer)
+
+	if err != nil {
+		t.Logf("BlacklistSet.WriteSnapshot: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	again := bytes.Buffer{}
+	_ = set.WriteSnapshot(&again)
+
+	if !bytes.Equal(buffer.Bytes(), again.Bytes()) {
+		t.Errorf("BlacklistSet.WriteSnapshot: expected the same set to produce the same snapshot")
+	}
+
+	loaded, err := ReadBlacklistSnapshot(bytes.NewReader(buffer.Bytes()))
+
+	if err != nil {
+		t.Logf("ReadBlacklistSnapshot: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if !loaded.GeneratedAt().Equal(set.GeneratedAt()) {
+		t.Errorf("ReadBlacklistSnapshot: expected generatedAt %v, got %v", set.GeneratedAt(), loaded.GeneratedAt())
+	}
+
+	if loaded.Stats() != set.Stats() {
+		t.Errorf("ReadBlacklistSnapshot: expected stats %+v, got %+v", set.Stats(), loaded.Stats())
+	}
+
+	match, ok := loaded.Lookup(net.ParseIP("2606:4700::1111"))
+
+	if !ok || match.AbuseConfidenceScore != 75 || !match.LastReportedAt.Equal(time.Date(2021, 8, 17, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("ReadBlacklistSnapshot: expected score 75 reported at 2021-08-17 09:00:00, got %+v", match)
+	}
+
+	match, ok = loaded.Lookup(net.ParseIP("2001:db8:1::1"))
+
+	if !ok || match.AbuseConfidenceScore != 60 || match.Network != "2001:db8::/32" {
+		t.Errorf("ReadBlacklistSnapshot: expected 2001:db8:1::1 to match 2001:db8::/32 with score 60, got %+v", match)
+	}
+
+	if !loaded.Contains(net.ParseIP("192.0.2.77")) {
+		t.Errorf("ReadBlacklistSnapshot: expected 192.0.2.77 to match 192.0.2.0/24")
+	}
+
+	corrupt := append([]byte(nil), buffer.Bytes()...)
+	corrupt[len(corrupt)/2] ^= 0xff
+
+	_, err = ReadBlacklistSnapshot(bytes.NewReader(corrupt))
+
+	if !errors.Is(err, ErrInvalidSnapshot) {
+		t.Errorf("ReadBlacklistSnapshot: expected ErrInvalidSnapshot for a corrupt snapshot, got %v", err)
+	}
+
+	_, err = ReadBlacklistSnapshot(bytes.NewReader(buffer.Bytes()[:buffer.Len()-1]))
+
+	if !errors.Is(err, ErrInvalidSnapshot) {
+		t.Errorf("ReadBlacklistSnapshot: expected ErrInvalidSnapshot for a truncated snapshot, got %v", err)
+	}
+
+	_, err = ReadBlacklistSnapshot(strings.NewReader(testBlacklistJSON))
+
+	if !errors.Is(err, ErrInvalidSnapshot) {
+		t.Errorf("ReadBlacklistSnapshot: expected ErrInvalidSnapshot for a JSON blacklist, got %v", err)
+	}
+}
+
+func TestSaveBlacklistSet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "abuseipdb")
+
+	if err != nil {
+		t.Logf("ioutil.TempDir: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "blacklist.snapshot")
+	set, _ := ReadBlacklistSet(strings.NewReader(testBlacklistJSON))
+
+	err = SaveBlacklistSet(path, set)
+
+	if err != nil {
+		t.Logf("SaveBlacklistSet: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+
+	if len(files) != 1 {
+		t.Errorf("SaveBlacklistSet: expected the temporary file to be removed, found %d files", len(files))
+	} else if files[0].Mode().Perm() != 0644 {
+		t.Errorf("SaveBlacklistSet: expected a new snapshot to be readable by other users, got %v", files[0].Mode().Perm())
+	}
+
+	// Saving again keeps the permissions of the existing snapshot.
+	_ = os.Chmod(path, 0640)
+
+	if err := SaveBlacklistSet(path, set); err != nil {
+		t.Logf("SaveBlacklistSet: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm() != 0640 {
+		t.Errorf("SaveBlacklistSet: expected the file mode to be kept as 0640, got %v", info.Mode().Perm())
+	}
+
+	loaded, err := LoadBlacklistSet(path)
+
+	if err != nil {
+		t.Logf("LoadBlacklistSet: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	if loaded.Len() != 3 || !loaded.Contains(net.ParseIP("8.8.8.8")) {
+		t.Errorf("LoadBlacklistSet: expected 3 entries including 8.8.8.8, got %d", loaded.Len())
+	}
+
+	_, err = LoadBlacklistSet(filepath.Join(dir, "missing"))
+
+	if !os.IsNotExist(err) {
+		t.Errorf("LoadBlacklistSet: expected a not exist error, got %v", err)
+	}
+}
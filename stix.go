@@ -0,0 +1,321 @@
+package abuseipdb
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+const stixSpecVersion = "2.1"
+
+var (
+	// stixNamespace is the namespace of the UUIDs generated for the STIX objects exported by this package,
+	// so the same data always produces the same identifiers.
+	stixNamespace = [16]byte{0xe3, 0x4b, 0xb1, 0x07, 0x5d, 0x78, 0x4f, 0x82, 0x89, 0x4c, 0xca, 0xba, 0x1c, 0x2d, 0x9e, 0xba}
+	// stixCyberObservableNamespace is the namespace defined by STIX 2.1 for the identifiers of cyber-observable objects.
+	stixCyberObservableNamespace = [16]byte{0x00, 0xab, 0xed, 0xb4, 0xaa, 0x42, 0x46, 0x6c, 0x9c, 0x01, 0xfe, 0xd2, 0x33, 0x15, 0xa9, 0xb7}
+	// stixIdentityCreated is the creation time of the identity representing AbuseIPDB.
+	stixIdentityCreated = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// STIXIdentityID is the identifier of the STIX identity representing AbuseIPDB, which created the exported objects.
+var STIXIdentityID = stixID("identity", "AbuseIPDB")
+
+// STIXTimestamp is a time which is encoded in JSON as a STIX timestamp, in UTC with millisecond precision.
+type STIXTimestamp time.Time
+
+// MarshalJSON implements json.Marshaler.
+func (t STIXTimestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(stixTimestamp(time.Time(t)))
+}
+
+// stixTimestamp formats a time as a STIX timestamp.
+func stixTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *STIXTimestamp) UnmarshalJSON(data []byte) error {
+	parsed := time.Time{}
+	err := json.Unmarshal(data, &parsed)
+	*t = STIXTimestamp(parsed)
+
+	return err
+}
+
+// STIXBundle represents a STIX 2.1 bundle, which holds a collection of STIX objects.
+type STIXBundle struct {
+	Type    string        `json:"type"`
+	ID      string        `json:"id"`
+	Objects []interface{} `json:"objects"`
+}
+
+// STIXExternalReference represents a reference from a STIX object to a source outside of STIX.
+type STIXExternalReference struct {
+	SourceName string `json:"source_name"`
+	URL        string `json:"url,omitempty"`
+}
+
+// STIXIdentity represents a STIX 2.1 identity object.
+type STIXIdentity struct {
+	Type          string        `json:"type"`
+	SpecVersion   string        `json:"spec_version"`
+	ID            string        `json:"id"`
+	Created       STIXTimestamp `json:"created"`
+	Modified      STIXTimestamp `json:"modified"`
+	Name          string        `json:"name"`
+	IdentityClass string        `json:"identity_class"`
+}
+
+// STIXIndicator represents a STIX 2.1 indicator object.
+type STIXIndicator struct {
+	Type               string                  `json:"type"`
+	SpecVersion        string                  `json:"spec_version"`
+	ID                 string                  `json:"id"`
+	CreatedByRef       string                  `json:"created_by_ref"`
+	Created            STIXTimestamp           `json:"created"`
+	Modified           STIXTimestamp           `json:"modified"`
+	Name               string                  `json:"name"`
+	IndicatorTypes     []string                `json:"indicator_types"`
+	Pattern            string                  `json:"pattern"`
+	PatternType        string                  `json:"pattern_type"`
+	ValidFrom          STIXTimestamp           `json:"valid_from"`
+	Confidence         int                     `json:"confidence"`
+	ExternalReferences []STIXExternalReference `json:"external_references"`
+}
+
+// STIXIPAddress represents a STIX 2.1 ipv4-addr or ipv6-addr cyber-observable object.
+type STIXIPAddress struct {
+	Type        string `json:"type"`
+	SpecVersion string `json:"spec_version"`
+	ID          string `json:"id"`
+	Value       string `json:"value"`
+}
+
+// STIXObservedData represents a STIX 2.1 observed-data object, recording the reports made to AbuseIPDB about an IP.
+// The names of the categories reported are used as labels, and the AbuseIPDB specific properties use the custom
+// property prefix "x_abuseipdb_".
+type STIXObservedData struct {
+	Type                 string                  `json:"type"`
+	SpecVersion          string                  `json:"spec_version"`
+	ID                   string                  `json:"id"`
+	CreatedByRef         string                  `json:"created_by_ref"`
+	Created              STIXTimestamp           `json:"created"`
+	Modified             STIXTimestamp           `json:"modified"`
+	FirstObserved        STIXTimestamp           `json:"first_observed"`
+	LastObserved         STIXTimestamp           `json:"last_observed"`
+	NumberObserved       int                     `json:"number_observed"`
+	ObjectRefs           []string                `json:"object_refs"`
+	Confidence           int                     `json:"confidence"`
+	Labels               []string                `json:"labels,omitempty"`
+	ExternalReferences   []STIXExternalReference `json:"external_references"`
+	AbuseConfidenceScore int                     `json:"x_abuseipdb_abuse_confidence_score"`
+	TotalReports         int                     `json:"x_abuseipdb_total_reports"`
+	NumDistinctUsers     int                     `json:"x_abuseipdb_num_distinct_users"`
+	CountryCode          string                  `json:"x_abuseipdb_country_code,omitempty"`
+	ISP                  string                  `json:"x_abuseipdb_isp,omitempty"`
+	UsageType            string                  `json:"x_abuseipdb_usage_type,omitempty"`
+}
+
+// NewSTIXIdentity returns the STIX identity representing AbuseIPDB, which is referenced by the exported objects.
+func NewSTIXIdentity() STIXIdentity {
+	return STIXIdentity{
+		Type:          "identity",
+		SpecVersion:   stixSpecVersion,
+		ID:            STIXIdentityID,
+		Created:       STIXTimestamp(stixIdentityCreated),
+		Modified:      STIXTimestamp(stixIdentityCreated),
+		Name:          "AbuseIPDB",
+		IdentityClass: "organization",
+	}
+}
+
+// BlacklistIndicators returns a STIX indicator for each entry in the blacklist, in address order,
+// with the abuse confidence score used as the confidence. Entries can be filtered with ExportMinScore.
+// An indicator is valid from the time the IP was last reported, or the time the blacklist was generated if that isn't
+// known. Indicators are identified by their address, last report time and score, so an unchanged entry always
+// produces the same indicator.
+func BlacklistIndicators(response *BlacklistResponse, options ...ExportOption) []STIXIndicator {
+	config := newExportConfig(options)
+	ipv4, ipv6 := exportEntries(response, config)
+	indicators := make([]STIXIndicator, 0, len(ipv4)+len(ipv6))
+
+	for _, entry := range append(ipv4, ipv6...) {
+		validFrom := entry.LastReportedAt
+
+		if validFrom.IsZero() {
+			validFrom = response.Meta.GeneratedAt
+		}
+
+		indicators = append(indicators, STIXIndicator{
+			Type:               "indicator",
+			SpecVersion:        stixSpecVersion,
+			ID:                 stixID("indicator", fmt.Sprintf("%s|%d|%d", entry.IPAddress, validFrom.Unix(), entry.AbuseConfidenceScore)),
+			CreatedByRef:       STIXIdentityID,
+			Created:            STIXTimestamp(validFrom),
+			Modified:           STIXTimestamp(validFrom),
+			Name:               "AbuseIPDB reported address " + entry.IPAddress,
+			IndicatorTypes:     []string{"malicious-activity"},
+			Pattern:            fmt.Sprintf("[%s:value = '%s']", stixAddressType(entry.IPAddress), entry.IPAddress),
+			PatternType:        "stix",
+			ValidFrom:          STIXTimestamp(validFrom),
+			Confidence:         entry.AbuseConfidenceScore,
+			ExternalReferences: stixReferences(entry.IPAddress),
+		})
+	}
+
+	return indicators
+}
+
+// CheckObservation returns a STIX cyber-observable object for the IP in a CheckResponse, and an observed-data object
+// summarising the reports made about it, with the abuse confidence score used as the confidence.
+// The observed-data object is nil if the IP hasn't been reported. The categories of the reports are only included if
+// the IP was checked with the Verbose option. A nil response, such as that of a failed check, returns an empty
+// address and no observed-data object.
+func CheckObservation(response *CheckResponse) (STIXIPAddress, *STIXObservedData) {
+	if response == nil {
+		return STIXIPAddress{}, nil
+	}
+
+	ipAddress := canonicalIP(response.Data.IPAddress)
+	addressType := stixAddressType(ipAddress)
+	address := STIXIPAddress{
+		Type:        addressType,
+		SpecVersion: stixSpecVersion,
+		ID:          stixObservableID(addressType, ipAddress),
+		Value:       ipAddress,
+	}
+
+	if response.Data.TotalReports < 1 || response.Data.LastReportedAt.IsZero() {
+		return address, nil
+	}
+
+	firstObserved := response.Data.LastReportedAt
+	seen := make(map[Category]bool)
+	var categories []string
+
+	for _, report := range response.Data.Reports {
+		if !report.ReportedAt.IsZero() && report.ReportedAt.Before(firstObserved) {
+			firstObserved = report.ReportedAt
+		}
+
+		for _, category := range report.Categories {
+			if !seen[Category(category)] {
+				seen[Category(category)] = true
+				categories = append(categories, Category(category).String())
+			}
+		}
+	}
+
+	sort.Strings(categories)
+
+	observedData := STIXObservedData{
+		Type:                 "observed-data",
+		SpecVersion:          stixSpecVersion,
+		ID:                   stixID("observed-data", fmt.Sprintf("%s|%d|%d", ipAddress, response.Data.LastReportedAt.Unix(), response.Data.TotalReports)),
+		CreatedByRef:         STIXIdentityID,
+		Created:              STIXTimestamp(response.Data.LastReportedAt),
+		Modified:             STIXTimestamp(response.Data.LastReportedAt),
+		FirstObserved:        STIXTimestamp(firstObserved),
+		LastObserved:         STIXTimestamp(response.Data.LastReportedAt),
+		NumberObserved:       response.Data.TotalReports,
+		ObjectRefs:           []string{address.ID},
+		Confidence:           response.Data.AbuseConfidenceScore,
+		Labels:               categories,
+		ExternalReferences:   stixReferences(ipAddress),
+		AbuseConfidenceScore: response.Data.AbuseConfidenceScore,
+		TotalReports:         response.Data.TotalReports,
+		NumDistinctUsers:     response.Data.NumDistinctUsers,
+		CountryCode:          response.Data.CountryCode,
+		ISP:                  response.Data.ISP,
+		UsageType:            response.Data.UsageType,
+	}
+
+	return address, &observedData
+}
+
+// NewSTIXBundle returns a STIX bundle holding the AbuseIPDB identity, an indicator for each entry in the blacklist and
+// the observations for each checked IP. Either the blacklist or the checks may be empty, and nil checks are left out.
+// The bundle is identified by the objects it holds, so the same data always produces the same bundle.
+func NewSTIXBundle(blacklist *BlacklistResponse, checks []*CheckResponse, options ...ExportOption) *STIXBundle {
+	objects := []interface{}{NewSTIXIdentity()}
+	ids := []string{STIXIdentityID}
+
+	for _, indicator := range BlacklistIndicators(blacklist, options...) {
+		objects = append(objects, indicator)
+		ids = append(ids, indicator.ID)
+	}
+
+	seen := make(map[string]bool)
+
+	for _, check := range checks {
+		if check == nil {
+			continue
+		}
+
+		address, observedData := CheckObservation(check)
+
+		if !seen[address.ID] {
+			seen[address.ID] = true
+			objects = append(objects, address)
+			ids = append(ids, address.ID)
+		}
+
+		if observedData != nil && !seen[observedData.ID] {
+			seen[observedData.ID] = true
+			objects = append(objects, *observedData)
+			ids = append(ids, observedData.ID)
+		}
+	}
+
+	return &STIXBundle{
+		Type:    "bundle",
+		ID:      stixID("bundle", strings.Join(ids, "|")),
+		Objects: objects,
+	}
+}
+
+func stixAddressType(ipAddress string) string {
+	if ip := net.ParseIP(ipAddress); ip != nil && ip.To4() == nil {
+		return "ipv6-addr"
+	}
+
+	return "ipv4-addr"
+}
+
+func stixReferences(ipAddress string) []STIXExternalReference {
+	return []STIXExternalReference{{
+		SourceName: "AbuseIPDB",
+		URL:        "https://www.abuseipdb.com/check/" + ipAddress,
+	}}
+}
+
+// stixID returns an identifier for a STIX object of the given type, derived from name.
+func stixID(objectType string, name string) string {
+	return objectType + "--" + uuid5(stixNamespace, name)
+}
+
+// stixObservableID returns the identifier defined by STIX 2.1 for an IP address cyber-observable object,
+// which is derived from its value.
+func stixObservableID(objectType string, value string) string {
+	name, _ := json.Marshal(map[string]string{"value": value})
+
+	return objectType + "--" + uuid5(stixCyberObservableNamespace, string(name))
+}
+
+// uuid5 returns the name-based UUID (version 5) for a name in a namespace, as described in RFC 4122.
+func uuid5(namespace [16]byte, name string) string {
+	hash := sha1.New()
+	hash.Write(namespace[:])
+	hash.Write([]byte(name))
+
+	sum := hash.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
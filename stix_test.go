@@ -0,0 +1,111 @@
+package abuseipdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUUID5(t *testing.T) {
+	id := stixObservableID("ipv4-addr", "1.1.1.1")
+
+	if id != "ipv4-addr--cbd67181-b9f8-595b-8bc3-3971e34fa1cc" {
+		t.Errorf("stixObservableID: expected ipv4-addr--cbd67181-b9f8-595b-8bc3-3971e34fa1cc, got %s", id)
+	}
+}
+
+func TestBlacklistIndicators(t *testing.T) {
+	indicators := BlacklistIndicators(testExportBlacklist(), ExportMinScore(75))
+
+	if len(indicators) != 2 {
+		t.Logf("BlacklistIndicators: expected 2 indicators, got %d", len(indicators))
+		t.FailNow()
+	}
+
+	if indicators[0].Pattern != "[ipv4-addr:value = '1.1.1.1']" || indicators[0].Confidence != 100 {
+		t.Errorf("BlacklistIndicators: expected IPv4 pattern with confidence 100, got %+v", indicators[0])
+	}
+
+	if indicators[1].Pattern != "[ipv6-addr:value = '2606:4700::1111']" || indicators[1].Confidence != 75 {
+		t.Errorf("BlacklistIndicators: expected IPv6 pattern with confidence 75, got %+v", indicators[1])
+	}
+
+	if again := BlacklistIndicators(testExportBlacklist(), ExportMinScore(75)); again[0].ID != indicators[0].ID {
+		t.Errorf("BlacklistIndicators: expected the same entry to produce the same ID")
+	}
+
+	encoded, _ := json.Marshal(indicators[0])
+	decoded := map[string]interface{}{}
+	_ = json.Unmarshal(encoded, &decoded)
+
+	if decoded["valid_from"] != "2021-08-18T09:00:00.000Z" || decoded["spec_version"] != "2.1" || decoded["created_by_ref"] != STIXIdentityID {
+		t.Errorf("BlacklistIndicators: expected valid_from with millisecond precision, spec_version and created_by_ref, got %s", encoded)
+	}
+}
+
+func TestCheckObservation(t *testing.T) {
+	response := CheckResponse{}
+	response.Data.IPAddress = "1.1.1.1"
+	response.Data.AbuseConfidenceScore = 80
+	response.Data.TotalReports = 3
+	response.Data.NumDistinctUsers = 2
+	response.Data.CountryCode = "AU"
+	response.Data.LastReportedAt = time.Date(2021, 8, 18, 9, 0, 0, 0, time.UTC)
+	response.Data.Reports = []Report{
+		{ReportedAt: time.Date(2021, 8, 16, 9, 0, 0, 0, time.UTC), Categories: []int{int(CategorySSH), int(CategoryBruteForce)}},
+		{ReportedAt: time.Date(2021, 8, 18, 9, 0, 0, 0, time.UTC), Categories: []int{int(CategorySSH)}},
+	}
+
+	address, observedData := CheckObservation(&response)
+
+	if address.Type != "ipv4-addr" || address.Value != "1.1.1.1" {
+		t.Errorf("CheckObservation: expected ipv4-addr for 1.1.1.1, got %+v", address)
+	}
+
+	if observedData == nil {
+		t.Logf("CheckObservation: expected observed-data for a reported IP")
+		t.FailNow()
+	}
+
+	if !reflect.DeepEqual(observedData.ObjectRefs, []string{address.ID}) || observedData.NumberObserved != 3 || observedData.Confidence != 80 {
+		t.Errorf("CheckObservation: expected observed-data referencing the address with 3 observations, got %+v", observedData)
+	}
+
+	if !time.Time(observedData.FirstObserved).Equal(time.Date(2021, 8, 16, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("CheckObservation: expected first_observed to be the earliest report, got %v", time.Time(observedData.FirstObserved))
+	}
+
+	if !reflect.DeepEqual(observedData.Labels, []string{"BruteForce", "SSH"}) {
+		t.Errorf("CheckObservation: expected labels [BruteForce SSH], got %v", observedData.Labels)
+	}
+
+	response.Data.TotalReports = 0
+
+	if _, observedData = CheckObservation(&response); observedData != nil {
+		t.Errorf("CheckObservation: expected no observed-data for an IP without reports")
+	}
+}
+
+func TestNewSTIXBundle(t *testing.T) {
+	check := CheckResponse{}
+	check.Data.IPAddress = "8.8.4.4"
+
+	bundle := NewSTIXBundle(testExportBlacklist(), []*CheckResponse{&check, nil, &check})
+
+	if bundle.Type != "bundle" || len(bundle.Objects) != 5 {
+		t.Errorf("NewSTIXBundle: expected a bundle with an identity, 3 indicators and an address, got %d objects", len(bundle.Objects))
+	}
+
+	if address, observedData := CheckObservation(nil); address.ID != "" || observedData != nil {
+		t.Errorf("CheckObservation: expected an empty address for a nil response, got %+v", address)
+	}
+
+	if again := NewSTIXBundle(testExportBlacklist(), []*CheckResponse{&check}); again.ID != bundle.ID {
+		t.Errorf("NewSTIXBundle: expected the same data to produce the same bundle ID")
+	}
+
+	if _, err := json.Marshal(bundle); err != nil {
+		t.Errorf("NewSTIXBundle: expected bundle to marshal, got %v", err)
+	}
+}
@@ -0,0 +1,281 @@
+package abuseipdb
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// The syslog facility and severity used for events by default: local0 and informational.
+const (
+	defaultSyslogFacility = 16
+	syslogSeverityInfo    = 6
+)
+
+// The defaults for how long a SyslogWriter waits to connect or send a message, and how many events from Hook it
+// queues while sending.
+const (
+	defaultSyslogTimeout   = 5 * time.Second
+	defaultSyslogQueueSize = 1024
+)
+
+// ErrSyslogQueueFull is passed to the callback set with SyslogErrors when an event from Hook is dropped because
+// the queue of events waiting to be sent is full.
+var ErrSyslogQueueFull = errors.New("abuseipdb: syslog queue is full, event dropped")
+
+// SyslogWriter sends events to a syslog server as RFC 5424 messages, formatted with FormatCEF or FormatLEEF.
+// Messages are sent one per datagram over UDP and unixgram sockets, and terminated by a newline over TCP and unix
+// stream sockets. A SyslogWriter is safe for concurrent use, and reconnects if sending a message fails.
+// Connecting and sending each give up after the timeout set with SyslogTimeout.
+// Use DialSyslog to initialise a new writer, and Close to stop it.
+type SyslogWriter struct {
+	network  string
+	address  string
+	format   func(Event) string
+	tag      string
+	facility int
+	hostname string
+	timeout  time.Duration
+	onError  func(error)
+
+	queue     chan Event
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+type syslogConfig struct {
+	format    func(Event) string
+	tag       string
+	facility  int
+	timeout   time.Duration
+	queueSize int
+	onError   func(error)
+}
+
+// SyslogOption sets an optional parameter when creating a SyslogWriter.
+type SyslogOption func(*syslogConfig)
+
+// SyslogFormat returns a SyslogOption that sets the function used to format events, such as FormatLEEF.
+// The default format is FormatCEF.
+func SyslogFormat(format func(Event) string) SyslogOption {
+	return func(config *syslogConfig) {
+		config.format = format
+	}
+}
+
+// SyslogTag returns a SyslogOption that sets the app name of the messages sent. The default tag is "abuseipdb".
+func SyslogTag(tag string) SyslogOption {
+	return func(config *syslogConfig) {
+		config.tag = tag
+	}
+}
+
+// SyslogFacility returns a SyslogOption that sets the facility of the messages sent, from 0 to 23.
+// The default facility is 16 (local0).
+func SyslogFacility(facility int) SyslogOption {
+	return func(config *syslogConfig) {
+		config.facility = facility
+	}
+}
+
+// SyslogTimeout returns a SyslogOption that sets how long to wait when connecting to the syslog server or sending
+// a message before giving up. The default timeout is 5 seconds.
+func SyslogTimeout(timeout time.Duration) SyslogOption {
+	return func(config *syslogConfig) {
+		config.timeout = timeout
+	}
+}
+
+// SyslogQueueSize returns a SyslogOption that sets how many events from Hook can wait to be sent.
+// Once the queue is full, further events are dropped until there is room. The default size is 1024.
+func SyslogQueueSize(size int) SyslogOption {
+	return func(config *syslogConfig) {
+		config.queueSize = size
+	}
+}
+
+// SyslogErrors returns a SyslogOption that sets a callback which is called whenever an event from Hook can't be sent,
+// or is dropped because the queue is full.
+func SyslogErrors(callback func(error)) SyslogOption {
+	return func(config *syslogConfig) {
+		config.onError = callback
+	}
+}
+
+// DialSyslog connects to the syslog server at address. The network may be "udp", "tcp", "unix" or "unixgram".
+func DialSyslog(network string, address string, options ...SyslogOption) (*SyslogWriter, error) {
+	config := syslogConfig{
+		format:    FormatCEF,
+		tag:       "abuseipdb",
+		facility:  defaultSyslogFacility,
+		timeout:   defaultSyslogTimeout,
+		queueSize: defaultSyslogQueueSize,
+		onError:   func(error) {},
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	if config.facility < 0 || config.facility > 23 {
+		return nil, fmt.Errorf("abuseipdb: invalid syslog facility %d", config.facility)
+	}
+
+	if config.queueSize < 1 {
+		config.queueSize = 1
+	}
+
+	hostname, _ := os.Hostname()
+
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	writer := SyslogWriter{
+		network:  network,
+		address:  address,
+		format:   config.format,
+		tag:      config.tag,
+		facility: config.facility,
+		hostname: hostname,
+		timeout:  config.timeout,
+		onError:  config.onError,
+		queue:    make(chan Event, config.queueSize),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	conn, err := net.DialTimeout(network, address, config.timeout)
+
+	if err != nil {
+		return nil, err
+	}
+
+	writer.conn = conn
+
+	go writer.run()
+
+	return &writer, nil
+}
+
+// Write formats the event and sends it to the syslog server.
+func (s *SyslogWriter) Write(event Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	message := fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+		s.facility*8+syslogSeverityInfo,
+		event.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		s.hostname,
+		s.tag,
+		os.Getpid(),
+		s.format(event),
+	)
+
+	if s.network == "tcp" || s.network == "unix" {
+		message += "\n"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		err := s.send(message)
+
+		if err == nil {
+			return nil
+		}
+
+		s.conn.Close()
+		s.conn = nil
+	}
+
+	conn, err := net.DialTimeout(s.network, s.address, s.timeout)
+
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+
+	return s.send(message)
+}
+
+// send writes a message to the current connection, giving up after the timeout. s.mu must be held.
+func (s *SyslogWriter) send(message string) error {
+	_ = s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	_, err := s.conn.Write([]byte(message))
+
+	return err
+}
+
+// Hook returns an EventHook which queues each event to be sent to the syslog server, for use in Client.Hooks.
+// Events are sent in the background, so a slow or unreachable server never holds up the client's calls.
+// Events which can't be sent, or are dropped because the queue is full, are reported to the callback set with
+// SyslogErrors. Events queued after Close are dropped.
+func (s *SyslogWriter) Hook() EventHook {
+	return func(event Event) {
+		select {
+		case <-s.done:
+		case s.queue <- event:
+		default:
+			s.onError(ErrSyslogQueueFull)
+		}
+	}
+}
+
+// run sends the events queued by Hook until the writer is closed, then sends those still in the queue.
+func (s *SyslogWriter) run() {
+	defer close(s.stopped)
+
+	for {
+		select {
+		case event := <-s.queue:
+			s.writeQueued(event)
+		case <-s.done:
+			for {
+				select {
+				case event := <-s.queue:
+					s.writeQueued(event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *SyslogWriter) writeQueued(event Event) {
+	if err := s.Write(event); err != nil {
+		s.onError(err)
+	}
+}
+
+// Close sends the events already queued by Hook, and then closes the connection to the syslog server.
+func (s *SyslogWriter) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+
+	<-s.stopped
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+
+	return err
+}
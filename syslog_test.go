@@ -0,0 +1,167 @@
+package abuseipdb
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSyslogWriter(t *testing.T) {
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Logf("net.ListenPacket: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	defer packetConn.Close()
+
+	writer, err := DialSyslog("udp", packetConn.LocalAddr().String(), SyslogTag("blocker"), SyslogFacility(4))
+
+	if err != nil {
+		t.Logf("DialSyslog: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	defer writer.Close()
+
+	writer.Hook()(testSIEMEvent())
+
+	buffer := make([]byte, 2048)
+	n, _, err := packetConn.ReadFrom(buffer)
+
+	if err != nil {
+		t.Logf("net.PacketConn.ReadFrom: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	message := string(buffer[:n])
+
+	if !strings.HasPrefix(message, "<38>1 2021-08-18T10:00:00.000000Z ") || !strings.Contains(message, " blocker ") ||
+		!strings.HasSuffix(message, " - - "+FormatCEF(testSIEMEvent())) {
+		t.Errorf("SyslogWriter.Write: expected an RFC 5424 message holding the CEF event, got %q", message)
+	}
+
+	_, err = DialSyslog("udp", packetConn.LocalAddr().String(), SyslogFacility(24))
+
+	if err == nil {
+		t.Errorf("DialSyslog: expected err to be non-nil for an invalid facility")
+	}
+}
+
+func TestSyslogWriter_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Logf("net.Listen: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	defer listener.Close()
+
+	lines := make(chan string, 2)
+
+	go func() {
+		conn, err := listener.Accept()
+
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		scanner := bufio.NewScanner(conn)
+
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	writer, err := DialSyslog("tcp", listener.Addr().String(), SyslogFormat(FormatLEEF))
+
+	if err != nil {
+		t.Logf("DialSyslog: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	defer writer.Close()
+
+	for i := 0; i < 2; i++ {
+		err = writer.Write(testSIEMEvent())
+
+		if err != nil {
+			t.Logf("SyslogWriter.Write: expected err to be nil, got %v", err)
+			t.FailNow()
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if line := <-lines; !strings.HasPrefix(line, "<134>1 ") || !strings.HasSuffix(line, FormatLEEF(testSIEMEvent())) {
+			t.Errorf("SyslogWriter.Write: expected newline framed LEEF messages, got %q", line)
+		}
+	}
+}
+
+func TestSyslogWriter_Hook(t *testing.T) {
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Logf("net.ListenPacket: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	defer packetConn.Close()
+
+	var dropped int32
+
+	writer, err := DialSyslog("udp", packetConn.LocalAddr().String(), SyslogQueueSize(1), SyslogErrors(func(err error) {
+		if err == ErrSyslogQueueFull {
+			atomic.AddInt32(&dropped, 1)
+		}
+	}))
+
+	if err != nil {
+		t.Logf("DialSyslog: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	// Holding the lock stalls sending, as a slow syslog server would.
+	writer.mu.Lock()
+
+	hook := writer.Hook()
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		hook(testSIEMEvent())
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("SyslogWriter.Hook: expected events to be queued without waiting, took %v", elapsed)
+	}
+
+	if atomic.LoadInt32(&dropped) == 0 {
+		t.Errorf("SyslogWriter.Hook: expected events to be dropped once the queue is full")
+	}
+
+	writer.mu.Unlock()
+	writer.Close()
+
+	_ = packetConn.SetReadDeadline(time.Now().Add(time.Second))
+	received := 0
+	buffer := make([]byte, 2048)
+
+	for {
+		if _, _, err := packetConn.ReadFrom(buffer); err != nil {
+			break
+		}
+
+		received++
+	}
+
+	if received+int(atomic.LoadInt32(&dropped)) != 3 {
+		t.Errorf("SyslogWriter.Close: expected the queued events to be sent, got %d sent and %d dropped", received, dropped)
+	}
+}
@@ -0,0 +1,383 @@
+package abuseipdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	taxiiMediaType = "application/taxii+json;version=2.1"
+	stixMediaType  = "application/stix+json;version=2.1"
+	// taxiiMaxPageSize is the largest number of objects returned in a single page, whatever limit is requested.
+	taxiiMaxPageSize = 1000
+)
+
+// TAXIICollectionID is the identifier of the collection served by TAXIIServer.
+var TAXIICollectionID = uuid5(stixNamespace, "AbuseIPDB blacklist")
+
+// TAXIIServer is an http.Handler serving the blacklist kept by a BlacklistRefresher as a read-only TAXII 2.1
+// collection of STIX indicators, so several tools can poll it instead of each spending their own quota.
+// It serves the discovery endpoint at /taxii2/, and an API root holding a single collection at /abuseipdb/,
+// or the path set with TAXIIAPIRoot. The server must be mounted at the root of the URL space.
+// Use NewTAXIIServer to initialise a new server.
+type TAXIIServer struct {
+	refresher     *BlacklistRefresher
+	apiRoot       string
+	title         string
+	pageSize      int
+	exportOptions []ExportOption
+
+	mu        sync.Mutex
+	set       *BlacklistSet
+	objects   []taxiiObject
+	dateAdded map[string]time.Time
+}
+
+// taxiiObject is a STIX object in the collection, with the time it was added.
+type taxiiObject struct {
+	id        string
+	dateAdded time.Time
+	object    interface{}
+}
+
+type taxiiConfig struct {
+	apiRoot       string
+	title         string
+	pageSize      int
+	exportOptions []ExportOption
+}
+
+// TAXIIOption sets an optional parameter when creating a TAXIIServer.
+type TAXIIOption func(*taxiiConfig)
+
+// TAXIIAPIRoot returns a TAXIIOption that sets the path of the API root. The default path is /abuseipdb/.
+func TAXIIAPIRoot(path string) TAXIIOption {
+	return func(config *taxiiConfig) {
+		config.apiRoot = path
+	}
+}
+
+// TAXIITitle returns a TAXIIOption that sets the title of the server, API root and collection.
+// The default title is "AbuseIPDB".
+func TAXIITitle(title string) TAXIIOption {
+	return func(config *taxiiConfig) {
+		config.title = title
+	}
+}
+
+// TAXIIPageSize returns a TAXIIOption that sets the number of objects returned in each page when the client doesn't
+// ask for fewer. The default value is 1000, which is also the maximum.
+func TAXIIPageSize(size int) TAXIIOption {
+	return func(config *taxiiConfig) {
+		config.pageSize = size
+	}
+}
+
+// TAXIIExportOptions returns a TAXIIOption that sets the options used to export the blacklist as indicators,
+// such as ExportMinScore.
+func TAXIIExportOptions(options ...ExportOption) TAXIIOption {
+	return func(config *taxiiConfig) {
+		config.exportOptions = options
+	}
+}
+
+// NewTAXIIServer initialises a new server for the blacklist kept by the refresher provided.
+// The refresher must be run separately.
+func NewTAXIIServer(refresher *BlacklistRefresher, options ...TAXIIOption) *TAXIIServer {
+	config := taxiiConfig{
+		apiRoot:  "/abuseipdb/",
+		title:    "AbuseIPDB",
+		pageSize: taxiiMaxPageSize,
+	}
+
+	for _, option := range options {
+		option(&config)
+	}
+
+	if config.pageSize < 1 || config.pageSize > taxiiMaxPageSize {
+		config.pageSize = taxiiMaxPageSize
+	}
+
+	server := TAXIIServer{
+		refresher:     refresher,
+		apiRoot:       "/" + strings.Trim(config.apiRoot, "/") + "/",
+		title:         config.title,
+		pageSize:      config.pageSize,
+		exportOptions: config.exportOptions,
+		dateAdded:     make(map[string]time.Time),
+	}
+
+	return &server
+}
+
+type taxiiError struct {
+	Title      string `json:"title"`
+	HTTPStatus string `json:"http_status"`
+}
+
+type taxiiCollection struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	CanRead     bool     `json:"can_read"`
+	CanWrite    bool     `json:"can_write"`
+	MediaTypes  []string `json:"media_types"`
+}
+
+type taxiiEnvelope struct {
+	More    bool          `json:"more"`
+	Next    string        `json:"next,omitempty"`
+	Objects []interface{} `json:"objects,omitempty"`
+}
+
+// ServeHTTP implements http.Handler.
+func (s *TAXIIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+
+		return
+	}
+
+	if !taxiiAcceptable(r.Header.Get("Accept")) {
+		s.writeError(w, http.StatusNotAcceptable, "The requested media type is not supported")
+
+		return
+	}
+
+	path := r.URL.Path
+
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+
+	collection := s.apiRoot + "collections/" + TAXIICollectionID + "/"
+
+	switch path {
+	case "/taxii2/":
+		scheme := "http"
+
+		if r.TLS != nil {
+			scheme = "https"
+		}
+
+		apiRoot := scheme + "://" + r.Host + s.apiRoot
+
+		s.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"title":     s.title,
+			"default":   apiRoot,
+			"api_roots": []string{apiRoot},
+		})
+	case s.apiRoot:
+		s.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"title":              s.title,
+			"versions":           []string{taxiiMediaType},
+			"max_content_length": 0,
+		})
+	case s.apiRoot + "collections/":
+		s.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"collections": []taxiiCollection{s.collection()},
+		})
+	case collection:
+		s.writeJSON(w, http.StatusOK, s.collection())
+	case collection + "objects/":
+		s.serveObjects(w, r)
+	default:
+		s.writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func (s *TAXIIServer) collection() taxiiCollection {
+	return taxiiCollection{
+		ID:          TAXIICollectionID,
+		Title:       s.title,
+		Description: "Indicators for the IP addresses in the AbuseIPDB blacklist",
+		CanRead:     true,
+		CanWrite:    false,
+		MediaTypes:  []string{stixMediaType},
+	}
+}
+
+// serveObjects serves a page of the objects in the collection, filtered by the added_after parameter.
+// The next parameter holds the date added and ID of the last object on the previous page, so pages stay consistent
+// when the blacklist is refreshed between requests.
+func (s *TAXIIServer) serveObjects(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := s.pageSize
+
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+
+		if err != nil || parsed < 1 {
+			s.writeError(w, http.StatusBadRequest, "Invalid limit")
+
+			return
+		}
+
+		if parsed < limit {
+			limit = parsed
+		}
+	}
+
+	var addedAfter time.Time
+
+	if value := query.Get("added_after"); value != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "Invalid added_after")
+
+			return
+		}
+
+		addedAfter = parsed
+	}
+
+	start := 0
+	objects := s.snapshot()
+
+	if !addedAfter.IsZero() {
+		start = sort.Search(len(objects), func(i int) bool {
+			return objects[i].dateAdded.After(addedAfter)
+		})
+	}
+
+	if value := query.Get("next"); value != "" {
+		parts := strings.SplitN(value, ":", 2)
+		nanos, err := strconv.ParseInt(parts[0], 10, 64)
+
+		if len(parts) != 2 || err != nil {
+			s.writeError(w, http.StatusBadRequest, "Invalid next")
+
+			return
+		}
+
+		cursor := taxiiObject{id: parts[1], dateAdded: time.Unix(0, nanos)}
+		next := sort.Search(len(objects), func(i int) bool {
+			return objects[i].after(cursor)
+		})
+
+		if next > start {
+			start = next
+		}
+	}
+
+	end := start + limit
+
+	if end > len(objects) {
+		end = len(objects)
+	}
+
+	page := objects[start:end]
+	envelope := taxiiEnvelope{More: end < len(objects)}
+
+	for _, object := range page {
+		envelope.Objects = append(envelope.Objects, object.object)
+	}
+
+	if len(page) > 0 {
+		first, last := page[0], page[len(page)-1]
+
+		w.Header().Set("X-TAXII-Date-Added-First", stixTimestamp(first.dateAdded))
+		w.Header().Set("X-TAXII-Date-Added-Last", stixTimestamp(last.dateAdded))
+
+		if envelope.More {
+			envelope.Next = fmt.Sprintf("%d:%s", last.dateAdded.UnixNano(), last.id)
+		}
+	}
+
+	s.writeJSON(w, http.StatusOK, envelope)
+}
+
+// after reports whether the object comes after another in the order of the collection.
+func (o taxiiObject) after(other taxiiObject) bool {
+	if !o.dateAdded.Equal(other.dateAdded) {
+		return o.dateAdded.After(other.dateAdded)
+	}
+
+	return o.id > other.id
+}
+
+// snapshot returns the objects in the collection, ordered by the time they were added and then by ID.
+// The objects are rebuilt whenever the refresher swaps in a new blacklist. Objects which were already in the
+// collection keep the time they were added, and new objects are added at the time the new blacklist was generated.
+func (s *TAXIIServer) snapshot() []taxiiObject {
+	set := s.refresher.Current()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if set == s.set {
+		return s.objects
+	}
+
+	added := set.GeneratedAt()
+
+	if added.IsZero() {
+		added = time.Now()
+	}
+
+	dateAdded := make(map[string]time.Time)
+	identity := NewSTIXIdentity()
+	objects := []taxiiObject{s.object(identity.ID, identity, added, dateAdded)}
+
+	for _, indicator := range BlacklistIndicators(set.Response(), s.exportOptions...) {
+		objects = append(objects, s.object(indicator.ID, indicator, added, dateAdded))
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[j].after(objects[i])
+	})
+
+	s.set = set
+	s.objects = objects
+	s.dateAdded = dateAdded
+
+	return objects
+}
+
+func (s *TAXIIServer) object(id string, object interface{}, added time.Time, dateAdded map[string]time.Time) taxiiObject {
+	if previous, ok := s.dateAdded[id]; ok {
+		added = previous
+	}
+
+	dateAdded[id] = added
+
+	return taxiiObject{id: id, dateAdded: added, object: object}
+}
+
+func (s *TAXIIServer) writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", taxiiMediaType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (s *TAXIIServer) writeError(w http.ResponseWriter, status int, title string) {
+	s.writeJSON(w, status, taxiiError{
+		Title:      title,
+		HTTPStatus: strconv.Itoa(status),
+	})
+}
+
+// taxiiAcceptable reports whether an Accept header allows a TAXII 2.1 response.
+func taxiiAcceptable(accept string) bool {
+	if accept == "" {
+		return true
+	}
+
+	for _, mediaType := range strings.Split(accept, ",") {
+		mediaType = strings.ToLower(strings.Join(strings.Fields(mediaType), ""))
+
+		if strings.HasPrefix(mediaType, "*/*") || strings.HasPrefix(mediaType, "application/*") ||
+			mediaType == "application/taxii+json" || strings.HasPrefix(mediaType, taxiiMediaType) {
+			return true
+		}
+	}
+
+	return false
+}
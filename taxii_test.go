@@ -0,0 +1,127 @@
+package abuseipdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTAXIIServer(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			fmt.Fprint(w, testBlacklistJSON)
+
+			return
+		}
+
+		fmt.Fprint(w, `{"meta":{"generatedAt":"2021-08-18T16:00:00+00:00"},"data":[
+			{"ipAddress":"1.1.1.1","abuseConfidenceScore":100,"lastReportedAt":"2021-08-18T09:00:00+00:00"},
+			{"ipAddress":"9.9.9.9","abuseConfidenceScore":90,"lastReportedAt":"2021-08-18T15:00:00+00:00"}
+		]}`)
+	})
+
+	refresher := NewBlacklistRefresher(client)
+
+	if err := refresher.Refresh(); err != nil {
+		t.Logf("BlacklistRefresher.Refresh: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	server := httptest.NewServer(NewTAXIIServer(refresher, TAXIIPageSize(3)))
+	defer server.Close()
+
+	get := func(path string, body interface{}) *http.Response {
+		request, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		request.Header.Set("Accept", taxiiMediaType)
+		response, err := http.DefaultClient.Do(request)
+
+		if err != nil {
+			t.Logf("http.Get: expected err to be nil, got %v", err)
+			t.FailNow()
+		}
+
+		defer response.Body.Close()
+
+		if body != nil {
+			_ = json.NewDecoder(response.Body).Decode(body)
+		}
+
+		return response
+	}
+
+	discovery := struct {
+		Default  string   `json:"default"`
+		APIRoots []string `json:"api_roots"`
+	}{}
+
+	if response := get("/taxii2/", &discovery); response.Header.Get("Content-Type") != taxiiMediaType {
+		t.Errorf("TAXIIServer: expected Content-Type %s, got %s", taxiiMediaType, response.Header.Get("Content-Type"))
+	}
+
+	if discovery.Default != server.URL+"/abuseipdb/" || len(discovery.APIRoots) != 1 {
+		t.Errorf("TAXIIServer: expected discovery to list the API root, got %+v", discovery)
+	}
+
+	collections := struct {
+		Collections []taxiiCollection `json:"collections"`
+	}{}
+
+	get("/abuseipdb/collections/", &collections)
+
+	if len(collections.Collections) != 1 || collections.Collections[0].ID != TAXIICollectionID || !collections.Collections[0].CanRead {
+		t.Errorf("TAXIIServer: expected a single readable collection, got %+v", collections)
+	}
+
+	objectsPath := "/abuseipdb/collections/" + TAXIICollectionID + "/objects/"
+	envelope := taxiiEnvelope{}
+	response := get(objectsPath, &envelope)
+
+	// The identity and the three indicators, split across pages of three.
+	if !envelope.More || envelope.Next == "" || len(envelope.Objects) != 3 {
+		t.Logf("TAXIIServer: expected a full first page with more to come, got %+v", envelope)
+		t.FailNow()
+	}
+
+	if response.Header.Get("X-TAXII-Date-Added-Last") != "2021-08-18T10:00:00.000Z" {
+		t.Errorf("TAXIIServer: expected objects to be added when the blacklist was generated, got %s", response.Header.Get("X-TAXII-Date-Added-Last"))
+	}
+
+	next := envelope.Next
+	envelope = taxiiEnvelope{}
+	get(objectsPath+"?next="+url.QueryEscape(next), &envelope)
+
+	if envelope.More || len(envelope.Objects) != 1 {
+		t.Errorf("TAXIIServer: expected the last page to hold one object, got %+v", envelope)
+	}
+
+	if err := refresher.Refresh(); err != nil {
+		t.Logf("BlacklistRefresher.Refresh: expected err to be nil, got %v", err)
+		t.FailNow()
+	}
+
+	envelope = taxiiEnvelope{}
+	get(objectsPath+"?added_after=2021-08-18T10:00:00.000Z", &envelope)
+
+	if len(envelope.Objects) != 1 {
+		t.Logf("TAXIIServer: expected only the new indicator after a refresh, got %+v", envelope)
+		t.FailNow()
+	}
+
+	if indicator, _ := envelope.Objects[0].(map[string]interface{}); indicator["pattern"] != "[ipv4-addr:value = '9.9.9.9']" {
+		t.Errorf("TAXIIServer: expected an indicator for 9.9.9.9, got %+v", envelope.Objects[0])
+	}
+
+	if response := get(objectsPath+"?limit=0", nil); response.StatusCode != http.StatusBadRequest {
+		t.Errorf("TAXIIServer: expected status 400 for an invalid limit, got %d", response.StatusCode)
+	}
+
+	if response := get("/abuseipdb/collections/unknown/", nil); response.StatusCode != http.StatusNotFound {
+		t.Errorf("TAXIIServer: expected status 404 for an unknown collection, got %d", response.StatusCode)
+	}
+}